@@ -0,0 +1,343 @@
+// Package config carga la configuración del servidor desde un fichero JSON
+// opcional y variables de entorno (las variables tienen prioridad)
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Variables de entorno reconocidas
+const (
+	EnvFichero           = "JUEGO_CONFIG"
+	EnvDBDriver          = "JUEGO_DB_DRIVER"
+	EnvDBDSN             = "JUEGO_DB_DSN"
+	EnvDireccion         = "JUEGO_DIRECCION"
+	EnvModoGin           = "JUEGO_MODO_GIN"
+	EnvTiempoInactividad = "JUEGO_TIEMPO_INACTIVIDAD"
+	EnvCORSOrigenes      = "JUEGO_CORS_ORIGENES"
+	EnvEnlaceInvitacion  = "JUEGO_ENLACE_INVITACION"
+	EnvRangoInicial      = "JUEGO_EMPAREJAMIENTO_RANGO"
+	EnvAmpliacionRango   = "JUEGO_EMPAREJAMIENTO_AMPLIACION"
+	EnvIntervaloRango    = "JUEGO_EMPAREJAMIENTO_INTERVALO"
+	EnvSesionAcceso      = "JUEGO_SESION_ACCESO"
+	EnvSesionRefresco    = "JUEGO_SESION_REFRESCO"
+	EnvSesionQR          = "JUEGO_SESION_QR"
+	EnvChatPalabras      = "JUEGO_CHAT_PALABRAS"
+	EnvChatMensajes      = "JUEGO_CHAT_MENSAJES"
+	EnvChatVentana       = "JUEGO_CHAT_VENTANA"
+	EnvChatLongitud      = "JUEGO_CHAT_LONGITUD"
+	EnvMCTSIteraciones   = "JUEGO_MONTECARLO_ITERACIONES"
+	EnvMCTSTiempo        = "JUEGO_MONTECARLO_TIEMPO"
+	EnvMCTSSemilla       = "JUEGO_MONTECARLO_SEMILLA"
+)
+
+// Config es la configuración completa del servidor
+type Config struct {
+	DB             DB             `json:"db"`
+	Direccion      string         `json:"direccion"` // Dirección en la que escucha el servidor (":8081")
+	ModoGin        string         `json:"modo_gin"`  // debug, release o test
+	Juegos         Juegos         `json:"juegos"`
+	Sesiones       Sesiones       `json:"sesiones"`
+	Emparejamiento Emparejamiento `json:"emparejamiento"`
+	Chat           Chat           `json:"chat"`
+	Montecarlo     Montecarlo     `json:"montecarlo"`
+	CORSOrigenes   []string       `json:"cors_origenes"` // Orígenes permitidos; "*" admite cualquiera sin credenciales
+}
+
+// DB indica qué almacenamiento usar y cómo conectarse
+type DB struct {
+	Driver string `json:"driver"` // memoria, sqlite o mysql
+	DSN    string `json:"dsn"`    // Vacío para usar la conexión por defecto del driver
+}
+
+// Juegos agrupa las opciones de las partidas
+type Juegos struct {
+	// TiempoInactividad da por abandonada una partida sin movimientos durante ese tiempo (0 la desactiva)
+	TiempoInactividad Duracion `json:"tiempo_inactividad"`
+	// EnlaceInvitacion es el enlace al que se añade el código de invitación (p. ej. "https://juego.example/unirse/")
+	EnlaceInvitacion string `json:"enlace_invitacion"`
+}
+
+// Emparejamiento controla cuánto se pueden separar las puntuaciones de los
+// jugadores que se emparejan. El rango crece con la espera para no dejar a nadie en cola
+type Emparejamiento struct {
+	RangoInicial        int      `json:"rango_inicial"`        // Diferencia de puntuación admitida al entrar en la cola
+	AmpliacionRango     int      `json:"ampliacion_rango"`     // Cuánto crece el rango en cada intervalo de espera
+	IntervaloAmpliacion Duracion `json:"intervalo_ampliacion"` // Cada cuánto crece el rango
+}
+
+// Chat son los límites de moderación del chat de las partidas
+type Chat struct {
+	PalabrasProhibidas []string `json:"palabras_prohibidas"`  // Se sustituyen por asteriscos
+	MensajesPorVentana int      `json:"mensajes_por_ventana"` // Mensajes que puede enviar un jugador en cada ventana (0 sin límite)
+	Ventana            Duracion `json:"ventana"`
+	LongitudMaxima     int      `json:"longitud_maxima"` // Caracteres por mensaje
+}
+
+// Montecarlo es el presupuesto de los bots que buscan con Monte Carlo. Con una
+// semilla fija y sin límite de tiempo juegan siempre igual, lo que sirve para pruebas
+type Montecarlo struct {
+	Iteraciones int      `json:"iteraciones"` // Partidas simuladas por movimiento (0 sin límite)
+	Tiempo      Duracion `json:"tiempo"`      // Tiempo por movimiento (0 sin límite)
+	Semilla     int64    `json:"semilla"`     // 0 para una semilla distinta en cada búsqueda
+}
+
+// Sesiones indica cuánto duran los tokens que se emiten al iniciar sesión
+type Sesiones struct {
+	DuracionAcceso   Duracion `json:"duracion_acceso"`
+	DuracionRefresco Duracion `json:"duracion_refresco"`
+	DuracionQR       Duracion `json:"duracion_qr"` // Tiempo para confirmar y usar un código QR de inicio de sesión
+}
+
+// Duracion es un time.Duration que en JSON se escribe como "30m", "1h"...
+type Duracion time.Duration
+
+func (d *Duracion) UnmarshalJSON(datos []byte) error {
+	var texto string
+	if err := json.Unmarshal(datos, &texto); err != nil {
+		return fmt.Errorf("la duración debe ser un texto como \"30m\": %w", err)
+	}
+	valor, err := time.ParseDuration(texto)
+	if err != nil {
+		return err
+	}
+	*d = Duracion(valor)
+	return nil
+}
+
+func (d Duracion) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// PorDefecto devuelve la configuración usada cuando no se indica nada
+func PorDefecto() *Config {
+	return &Config{
+		DB:        DB{Driver: "mysql"},
+		Direccion: ":8081",
+		ModoGin:   gin.DebugMode,
+		Juegos: Juegos{
+			TiempoInactividad: Duracion(24 * time.Hour),
+			EnlaceInvitacion:  "juego://unirse/",
+		},
+		Emparejamiento: Emparejamiento{
+			RangoInicial:        100,
+			AmpliacionRango:     50,
+			IntervaloAmpliacion: Duracion(10 * time.Second),
+		},
+		Chat: Chat{
+			MensajesPorVentana: 5,
+			Ventana:            Duracion(10 * time.Second),
+			LongitudMaxima:     500,
+		},
+		Montecarlo: Montecarlo{
+			Iteraciones: 20000,
+			Tiempo:      Duracion(2 * time.Second),
+		},
+		Sesiones: Sesiones{
+			DuracionAcceso:   Duracion(time.Hour),
+			DuracionRefresco: Duracion(30 * 24 * time.Hour),
+			DuracionQR:       Duracion(2 * time.Minute),
+		},
+	}
+}
+
+// Load lee el fichero indicado en JUEGO_CONFIG (si existe), aplica las variables
+// de entorno encima y valida el resultado
+func Load() (*Config, error) {
+	cfg := PorDefecto()
+
+	if fichero := os.Getenv(EnvFichero); fichero != "" {
+		if err := cfg.leerFichero(fichero); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.leerEntorno(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// leerFichero mezcla en la configuración los valores de un fichero JSON
+func (cfg *Config) leerFichero(fichero string) error {
+	datos, err := os.ReadFile(fichero)
+	if err != nil {
+		return fmt.Errorf("no se pudo leer el fichero de configuración %s: %w", fichero, err)
+	}
+	if err := json.Unmarshal(datos, cfg); err != nil {
+		return fmt.Errorf("el fichero de configuración %s no es JSON válido: %w", fichero, err)
+	}
+	return nil
+}
+
+// leerEntorno sobrescribe la configuración con las variables de entorno definidas
+func (cfg *Config) leerEntorno() error {
+	if valor, existe := os.LookupEnv(EnvDBDriver); existe {
+		cfg.DB.Driver = valor
+	}
+	if valor, existe := os.LookupEnv(EnvDBDSN); existe {
+		cfg.DB.DSN = valor
+	}
+	if valor, existe := os.LookupEnv(EnvDireccion); existe {
+		cfg.Direccion = valor
+	}
+	if valor, existe := os.LookupEnv(EnvModoGin); existe {
+		cfg.ModoGin = valor
+	}
+	duraciones := map[string]*Duracion{
+		EnvTiempoInactividad: &cfg.Juegos.TiempoInactividad,
+		EnvSesionAcceso:      &cfg.Sesiones.DuracionAcceso,
+		EnvSesionRefresco:    &cfg.Sesiones.DuracionRefresco,
+		EnvSesionQR:          &cfg.Sesiones.DuracionQR,
+		EnvIntervaloRango:    &cfg.Emparejamiento.IntervaloAmpliacion,
+		EnvChatVentana:       &cfg.Chat.Ventana,
+		EnvMCTSTiempo:        &cfg.Montecarlo.Tiempo,
+	}
+	for variable, destino := range duraciones {
+		if valor, existe := os.LookupEnv(variable); existe {
+			duracion, err := time.ParseDuration(valor)
+			if err != nil {
+				return fmt.Errorf("%s: %q no es una duración válida (ejemplo: 30m)", variable, valor)
+			}
+			*destino = Duracion(duracion)
+		}
+	}
+	enteros := map[string]*int{
+		EnvRangoInicial:    &cfg.Emparejamiento.RangoInicial,
+		EnvAmpliacionRango: &cfg.Emparejamiento.AmpliacionRango,
+		EnvChatMensajes:    &cfg.Chat.MensajesPorVentana,
+		EnvChatLongitud:    &cfg.Chat.LongitudMaxima,
+		EnvMCTSIteraciones: &cfg.Montecarlo.Iteraciones,
+	}
+	for variable, destino := range enteros {
+		if valor, existe := os.LookupEnv(variable); existe {
+			numero, err := strconv.Atoi(valor)
+			if err != nil {
+				return fmt.Errorf("%s: %q no es un número entero", variable, valor)
+			}
+			*destino = numero
+		}
+	}
+	if valor, existe := os.LookupEnv(EnvMCTSSemilla); existe {
+		semilla, err := strconv.ParseInt(valor, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %q no es un número entero", EnvMCTSSemilla, valor)
+		}
+		cfg.Montecarlo.Semilla = semilla
+	}
+	if valor, existe := os.LookupEnv(EnvEnlaceInvitacion); existe {
+		cfg.Juegos.EnlaceInvitacion = valor
+	}
+	if valor, existe := os.LookupEnv(EnvCORSOrigenes); existe {
+		cfg.CORSOrigenes = lista(valor)
+	}
+	if valor, existe := os.LookupEnv(EnvChatPalabras); existe {
+		cfg.Chat.PalabrasProhibidas = lista(valor)
+	}
+	return nil
+}
+
+// lista separa un valor de entorno por comas descartando los elementos vacíos
+func lista(valor string) []string {
+	var elementos []string
+	for _, elemento := range strings.Split(valor, ",") {
+		if elemento = strings.TrimSpace(elemento); elemento != "" {
+			elementos = append(elementos, elemento)
+		}
+	}
+	return elementos
+}
+
+// Validate revisa toda la configuración y devuelve todos los problemas encontrados a la vez
+func (cfg *Config) Validate() error {
+	var errs []error
+
+	switch cfg.DB.Driver {
+	case "memoria", "sqlite", "mysql":
+	default:
+		errs = append(errs, fmt.Errorf("db.driver (%s): %q no es válido, usa memoria, sqlite o mysql", EnvDBDriver, cfg.DB.Driver))
+	}
+
+	if _, puerto, err := net.SplitHostPort(cfg.Direccion); err != nil || puerto == "" {
+		errs = append(errs, fmt.Errorf("direccion (%s): %q no es válida, usa host:puerto o :puerto", EnvDireccion, cfg.Direccion))
+	}
+
+	switch cfg.ModoGin {
+	case gin.DebugMode, gin.ReleaseMode, gin.TestMode:
+	default:
+		errs = append(errs, fmt.Errorf("modo_gin (%s): %q no es válido, usa debug, release o test", EnvModoGin, cfg.ModoGin))
+	}
+
+	if cfg.Juegos.TiempoInactividad < 0 {
+		errs = append(errs, fmt.Errorf("juegos.tiempo_inactividad (%s) no puede ser negativo", EnvTiempoInactividad))
+	}
+	if enlace, err := url.Parse(cfg.Juegos.EnlaceInvitacion); err != nil || enlace.Scheme == "" {
+		errs = append(errs, fmt.Errorf("juegos.enlace_invitacion (%s): %q no es un enlace válido", EnvEnlaceInvitacion, cfg.Juegos.EnlaceInvitacion))
+	}
+
+	if cfg.Emparejamiento.RangoInicial < 0 {
+		errs = append(errs, fmt.Errorf("emparejamiento.rango_inicial (%s) no puede ser negativo", EnvRangoInicial))
+	}
+	if cfg.Emparejamiento.AmpliacionRango < 0 {
+		errs = append(errs, fmt.Errorf("emparejamiento.ampliacion_rango (%s) no puede ser negativa", EnvAmpliacionRango))
+	}
+	if cfg.Emparejamiento.IntervaloAmpliacion <= 0 {
+		errs = append(errs, fmt.Errorf("emparejamiento.intervalo_ampliacion (%s) debe ser mayor que cero", EnvIntervaloRango))
+	}
+
+	if cfg.Chat.MensajesPorVentana < 0 {
+		errs = append(errs, fmt.Errorf("chat.mensajes_por_ventana (%s) no puede ser negativo", EnvChatMensajes))
+	}
+	if cfg.Chat.MensajesPorVentana > 0 && cfg.Chat.Ventana <= 0 {
+		errs = append(errs, fmt.Errorf("chat.ventana (%s) debe ser mayor que cero", EnvChatVentana))
+	}
+	if cfg.Chat.LongitudMaxima <= 0 {
+		errs = append(errs, fmt.Errorf("chat.longitud_maxima (%s) debe ser mayor que cero", EnvChatLongitud))
+	}
+
+	if cfg.Montecarlo.Iteraciones < 0 {
+		errs = append(errs, fmt.Errorf("montecarlo.iteraciones (%s) no puede ser negativo", EnvMCTSIteraciones))
+	}
+	if cfg.Montecarlo.Tiempo < 0 {
+		errs = append(errs, fmt.Errorf("montecarlo.tiempo (%s) no puede ser negativo", EnvMCTSTiempo))
+	}
+	if cfg.Montecarlo.Iteraciones == 0 && cfg.Montecarlo.Tiempo == 0 {
+		errs = append(errs, fmt.Errorf("montecarlo: hace falta un límite de iteraciones (%s) o de tiempo (%s)", EnvMCTSIteraciones, EnvMCTSTiempo))
+	}
+
+	if cfg.Sesiones.DuracionAcceso <= 0 {
+		errs = append(errs, fmt.Errorf("sesiones.duracion_acceso (%s) debe ser mayor que cero", EnvSesionAcceso))
+	}
+	if cfg.Sesiones.DuracionRefresco < cfg.Sesiones.DuracionAcceso {
+		errs = append(errs, fmt.Errorf("sesiones.duracion_refresco (%s) no puede ser menor que la duración del acceso", EnvSesionRefresco))
+	}
+	if cfg.Sesiones.DuracionQR <= 0 {
+		errs = append(errs, fmt.Errorf("sesiones.duracion_qr (%s) debe ser mayor que cero", EnvSesionQR))
+	}
+
+	for _, origen := range cfg.CORSOrigenes {
+		if origen == "*" {
+			continue
+		}
+		if u, err := url.Parse(origen); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("cors_origenes (%s): %q no es un origen válido (ejemplo: http://localhost:3000)", EnvCORSOrigenes, origen))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("configuración no válida:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
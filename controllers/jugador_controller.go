@@ -1,7 +1,8 @@
 package controllers
 
 import (
-    "fmt"
+    "errors"
+    "juego/middleware"
     "juego/models"
     "juego/services"
     "net/http"
@@ -10,11 +11,13 @@ import (
 )
 
 type JugadorController struct {
-    JugadorService *services.JugadorService
+    JugadorService     *services.JugadorService
+    SesionService      *services.SesionService
+    EstadisticaService *services.EstadisticaService
 }
 
-func NewJugadorController(jugadorService *services.JugadorService) *JugadorController {
-    return &JugadorController{JugadorService: jugadorService}
+func NewJugadorController(jugadorService *services.JugadorService, sesionService *services.SesionService, estadisticaService *services.EstadisticaService) *JugadorController {
+    return &JugadorController{JugadorService: jugadorService, SesionService: sesionService, EstadisticaService: estadisticaService}
 }
 
 type RegisterInput struct {
@@ -42,7 +45,14 @@ func (controller *JugadorController) Register(c *gin.Context) {
         return
     }
 
-    c.JSON(http.StatusCreated, gin.H{"jugador": createdJugador})
+    // El jugador recién registrado ya queda con la sesión iniciada
+    tokens, err := controller.SesionService.IniciarSesion(createdJugador)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo iniciar la sesión"})
+        return
+    }
+
+    responderJugador(c, http.StatusCreated, controller.EstadisticaService, createdJugador, tokens)
 }
 
 
@@ -57,14 +67,72 @@ func (controller *JugadorController) Login(c *gin.Context) {
         return
     }
 
-    fmt.Printf("DEBUG LOGIN: email=%s, password=%s\n", loginInput.Email, loginInput.Password)
-
     jugador, err := controller.JugadorService.LoginJugador(loginInput.Email, loginInput.Password)
     if err != nil {
-        fmt.Printf("DEBUG LOGIN ERROR: %v\n", err)
         c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"jugador": jugador})
+    tokens, err := controller.SesionService.IniciarSesion(jugador)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo iniciar la sesión"})
+        return
+    }
+
+    responderJugador(c, http.StatusOK, controller.EstadisticaService, jugador, tokens)
+}
+
+// Refresh cambia el token de refresco por una sesión nueva
+func (controller *JugadorController) Refresh(c *gin.Context) {
+    var input struct {
+        RefreshToken string `json:"refresh_token" binding:"required"`
+    }
+
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Datos no válidos"})
+        return
+    }
+
+    tokens, jugador, err := controller.SesionService.Refrescar(input.RefreshToken)
+    if err != nil {
+        responderSesion(c, err)
+        return
+    }
+
+    responderJugador(c, http.StatusOK, controller.EstadisticaService, jugador, tokens)
+}
+
+// Logout invalida la sesión del token de acceso con el que se llama
+func (controller *JugadorController) Logout(c *gin.Context) {
+    token := middleware.TokenBearer(c)
+    if token == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Falta el token de acceso"})
+        return
+    }
+
+    if err := controller.SesionService.CerrarSesion(token); err != nil {
+        responderSesion(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Sesión cerrada"})
+}
+
+// responderJugador devuelve el jugador con su sesión y sus puntuaciones en cada tipo de juego
+func responderJugador(c *gin.Context, status int, estadisticaService *services.EstadisticaService, jugador *models.Jugador, tokens *services.Tokens) {
+    puntuaciones, err := estadisticaService.Puntuaciones(jugador.ID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(status, gin.H{"jugador": jugador, "sesion": tokens, "puntuaciones": puntuaciones})
+}
+
+func responderSesion(c *gin.Context, err error) {
+    if errors.Is(err, services.ErrSesionInvalida) {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+    c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 }
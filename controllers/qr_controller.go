@@ -2,62 +2,140 @@
 package controllers
 
 import (
+	"errors"
+	"juego/middleware"
+	"juego/qr"
+	"juego/services"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 )
 
-type QRController struct{}
+type QRController struct {
+	QRService          *services.QRService
+	EstadisticaService *services.EstadisticaService
+}
+
+func NewQRController(qrService *services.QRService, estadisticaService *services.EstadisticaService) *QRController {
+	return &QRController{QRService: qrService, EstadisticaService: estadisticaService}
+}
 
-func NewQRController() *QRController {
-	return &QRController{}
+type qrInput struct {
+	QRCode string `json:"qr_code" binding:"required"`
 }
 
-// Genera un código QR (solo devuelve un UUID)
+// Genera un código QR pendiente. El secreto se guarda en el dispositivo que
+// muestra el QR y hace falta para iniciar sesión con él.
+// Con ?formato=png|svg (y opcionalmente &tamano=256&nivel=L|M|Q|H) se devuelve
+// directamente la imagen del QR; el código, el secreto y la caducidad van en
+// las cabeceras X-QR-Code, X-QR-Secret y X-QR-Expires
 func (qc *QRController) GenerateQR(c *gin.Context) {
-	qrCode := uuid.New().String()
+	var imagen *qr.Opciones
+	if formato := c.Query("formato"); formato != "" {
+		opciones, err := qr.LeerOpciones(formato, c.Query("tamano"), c.Query("nivel"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		imagen = &opciones
+	}
+
+	codigo, secreto, err := qc.QRService.GenerarCodigo()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo generar el código QR"})
+		return
+	}
 
-	// Aquí podrías guardar el QR en memoria, Redis o una tabla temporal con estado "pendiente"
+	if imagen != nil {
+		datos, err := qr.Dibujar(codigo.Codigo, *imagen)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo dibujar el código QR"})
+			return
+		}
+		c.Header("X-QR-Code", codigo.Codigo)
+		c.Header("X-QR-Secret", secreto)
+		c.Header("X-QR-Expires", codigo.ExpiraEn.Format(time.RFC3339))
+		c.Header("Cache-Control", "no-store")
+		c.Data(http.StatusOK, imagen.TipoContenido(), datos)
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"qr_code": qrCode,
+		"qr_code":    codigo.Codigo,
+		"secret":     secreto,
+		"status":     codigo.Estado,
+		"expires_at": codigo.ExpiraEn,
 	})
 }
 
-// Verifica si el QR ha sido usado (simulado)
+// Consulta el estado del QR: pending, confirmed, consumed o expired
 func (qc *QRController) CheckQRStatus(c *gin.Context) {
-	var input struct {
-		QRCode string `json:"qr_code"`
+	var input qrInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "QR inválido"})
+		return
+	}
+
+	codigo, err := qc.QRService.EstadoCodigo(input.QRCode)
+	if err != nil {
+		responderQR(c, err)
+		return
 	}
 
-	if err := c.ShouldBindJSON(&input); err != nil || input.QRCode == "" {
+	c.JSON(http.StatusOK, gin.H{"status": codigo.Estado, "expires_at": codigo.ExpiraEn})
+}
+
+// Confirma el QR desde un dispositivo en el que el jugador ya ha iniciado sesión
+func (qc *QRController) ConfirmQR(c *gin.Context) {
+	var input qrInput
+	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "QR inválido"})
 		return
 	}
 
-	// Aquí simularías que aún no está usado
-	c.JSON(http.StatusOK, gin.H{"status": "pending"})
+	codigo, err := qc.QRService.ConfirmarCodigo(input.QRCode, middleware.JugadorActual(c))
+	if err != nil {
+		responderQR(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Código QR confirmado", "status": codigo.Estado})
 }
 
-// Login usando QR
+// Login usando QR: cambia un código confirmado por una sesión del jugador que lo confirmó
 func (qc *QRController) LoginWithQR(c *gin.Context) {
 	var input struct {
-		QRData    string `json:"qr_data"`
-		Timestamp int64  `json:"timestamp"`
+		QRData string `json:"qr_data" binding:"required"`
+		Secret string `json:"secret" binding:"required"`
 	}
 
-	if err := c.ShouldBindJSON(&input); err != nil || input.QRData == "" {
+	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
 		return
 	}
-	
 
-	c.JSON(http.StatusOK, gin.H{
-		"jugador": gin.H{
-			"id":    1,
-			"nombre": "JugadorQR",
-			"email": "qr@example.com",
-		},
-	})
+	tokens, jugador, err := qc.QRService.IniciarSesion(input.QRData, input.Secret)
+	if err != nil {
+		responderQR(c, err)
+		return
+	}
+
+	responderJugador(c, http.StatusOK, qc.EstadisticaService, jugador, tokens)
+}
+
+// responderQR traduce los errores del flujo QR a su código HTTP
+func responderQR(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrQRNoEncontrado):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrQRCaducado):
+		status = http.StatusGone
+	case errors.Is(err, services.ErrQRUsado), errors.Is(err, services.ErrQRNoConfirmado):
+		status = http.StatusConflict
+	case errors.Is(err, services.ErrQRSecreto):
+		status = http.StatusForbidden
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
 }
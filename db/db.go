@@ -5,34 +5,75 @@ import (
 	"log"
 	"juego/models"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 var DB *gorm.DB
 
-func Connect() {
-	username := "root"
-	password := ""
-	host := "127.0.0.1"
-	port := "3306"
-	dbname := "juego"
+// MySQLDSN es la conexión por defecto a la base de datos MySQL local
+const MySQLDSN = "root:@tcp(127.0.0.1:3306)/juego?charset=utf8mb4&parseTime=True&loc=Local"
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		username, password, host, port, dbname)
+// SQLiteDSN es el fichero por defecto para desarrollo local
+const SQLiteDSN = "gorm.db"
+
+// Connect abre la base de datos con el driver indicado ("mysql" o "sqlite") y migra los modelos
+func Connect(driver, dsn string) error {
+	var dialector gorm.Dialector
+	switch driver {
+	case "mysql":
+		if dsn == "" {
+			dsn = MySQLDSN
+		}
+		dialector = mysql.Open(dsn)
+	case "sqlite":
+		if dsn == "" {
+			dsn = SQLiteDSN
+		}
+		dialector = sqlite.Open(dsn)
+	default:
+		return fmt.Errorf("driver de base de datos desconocido: %q", driver)
+	}
 
 	var err error
-	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	DB, err = gorm.Open(dialector, &gorm.Config{})
+	if err == nil {
+		err = ping(DB)
+	}
 	if err != nil {
-		log.Fatal("❌ Error al conectar con la base de datos:", err)
+		return fmt.Errorf("❌ No se pudo conectar con la base de datos %s; revisa el DSN y que el servidor esté arrancado (%w)", driver, err)
 	}
 
-	err = DB.AutoMigrate(&models.Jugador{})
+	err = DB.AutoMigrate(
+		&models.Jugador{},
+		&models.Partida{},
+		&models.Participante{},
+		&models.TableroPartida{},
+		&models.Movimiento{},
+		&models.Sesion{},
+		&models.CodigoQR{},
+		&models.MensajeChat{},
+		&models.Silencio{},
+		&models.Estadistica{},
+		&models.HistorialPuntuacion{},
+	)
 	if err != nil {
-		log.Fatal("❌ Error al migrar modelos:", err)
+		return fmt.Errorf("❌ Error al migrar modelos: %w", err)
 	}
 
-	log.Println("✅ Conexión exitosa a la base de datos.")
+	log.Printf("✅ Conexión exitosa a la base de datos (%s).", driver)
+	return nil
+}
+
+// ping comprueba que la conexión funciona antes de migrar
+func ping(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Ping()
 }
+
 func Close() error {
 	if DB != nil {
 		sqlDB, err := DB.DB()
@@ -42,4 +83,4 @@ func Close() error {
 		return sqlDB.Close()
 	}
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"errors"
+	"juego/models"
+)
+
+// TipoConectaCuatro identifica al juego Conecta Cuatro
+const TipoConectaCuatro = "conecta_cuatro"
+
+var ErrColumnaLlena = errors.New("La columna está llena")
+
+// MovimientoConecta es la columna en la que el jugador deja caer su ficha
+type MovimientoConecta struct {
+	Columna int `json:"columna"`
+}
+
+// ConectaCuatro implementa las reglas de Conecta Cuatro sobre un tablero de 6x7
+type ConectaCuatro struct{}
+
+func init() {
+	Register(ConectaCuatro{})
+}
+
+func (ConectaCuatro) Type() string { return TipoConectaCuatro }
+
+func (ConectaCuatro) Seats() int { return 2 }
+
+func (ConectaCuatro) NewState(id string, jugadores []models.Jugador) Game {
+	return &models.ConectaCuatro{
+		Juego:     nuevoJuego(id, TipoConectaCuatro),
+		Jugadores: jugadores,
+		Turno:     0,
+	}
+}
+
+func (ConectaCuatro) NewMove() Move { return &MovimientoConecta{} }
+
+func (ConectaCuatro) ValidateMove(g Game, seat int, m Move) error {
+	juego := g.(*models.ConectaCuatro)
+	movimiento := m.(*MovimientoConecta)
+
+	if seat != juego.Turno {
+		return ErrFueraDeTurno
+	}
+	if movimiento.Columna < 0 || movimiento.Columna >= 7 {
+		return ErrMovimientoInvalido
+	}
+	if juego.Tablero[0][movimiento.Columna] != "" {
+		return ErrColumnaLlena
+	}
+	return nil
+}
+
+func (ConectaCuatro) ApplyMove(g Game, seat int, m Move) {
+	juego := g.(*models.ConectaCuatro)
+	movimiento := m.(*MovimientoConecta)
+
+	// La ficha cae hasta la primera fila libre empezando por abajo
+	for fila := 5; fila >= 0; fila-- {
+		if juego.Tablero[fila][movimiento.Columna] == "" {
+			juego.Tablero[fila][movimiento.Columna] = fichas[juego.Turno]
+			break
+		}
+	}
+	juego.Turno = (juego.Turno + 1) % 2
+}
+
+func (ConectaCuatro) Outcome(g Game) Outcome {
+	juego := g.(*models.ConectaCuatro)
+	celda := func(fila, columna int) string { return juego.Tablero[fila][columna] }
+
+	if ganador := ganadorTablero(6, 7, celda); ganador != NoWinner {
+		return Outcome{Finished: true, Winner: ganador}
+	}
+	// Si la fila de arriba está completa no caben más fichas: empate
+	for columna := 0; columna < 7; columna++ {
+		if juego.Tablero[0][columna] == "" {
+			return Outcome{Winner: NoWinner}
+		}
+	}
+	return Outcome{Finished: true, Winner: NoWinner}
+}
+
+func (ConectaCuatro) CurrentPlayer(g Game) int {
+	return g.(*models.ConectaCuatro).Turno
+}
+
+// LegalMoves devuelve las columnas que aún tienen hueco, de izquierda a derecha
+func (ConectaCuatro) LegalMoves(g Game) []Move {
+	juego := g.(*models.ConectaCuatro)
+	var movimientos []Move
+	for columna := 0; columna < 7; columna++ {
+		if juego.Tablero[0][columna] == "" {
+			movimientos = append(movimientos, &MovimientoConecta{Columna: columna})
+		}
+	}
+	return movimientos
+}
+
+// Clone copia el tablero; los jugadores se comparten porque los movimientos no los cambian
+func (ConectaCuatro) Clone(g Game) Game {
+	copia := *g.(*models.ConectaCuatro)
+	return &copia
+}
@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"errors"
+	"juego/models"
+	"testing"
+)
+
+// conectaCuatro crea una partida de Conecta Cuatro con el tablero dibujado
+func conectaCuatro(t *testing.T, turno int, dibujo ...string) *models.ConectaCuatro {
+	t.Helper()
+	juego := ConectaCuatro{}.NewState("prueba", []models.Jugador{{ID: 1}, {ID: 2}}).(*models.ConectaCuatro)
+	juego.Tablero = tablero6x7(t, dibujo...)
+	juego.Turno = turno
+	return juego
+}
+
+// Tablero lleno sin cuatro en línea: filas alternas que nunca repiten más de dos fichas
+var conectaEmpate = []string{"XXOOXXO", "OOXXOOX", "XXOOXXO", "OOXXOOX", "XXOOXXO", "OOXXOOX"}
+
+func TestConectaCuatroValidateMove(t *testing.T) {
+	// La columna 0 está llena y la 1 tiene un hueco arriba del todo
+	columnas := []string{"X......", "OX.....", "XO.....", "OX.....", "XO.....", "OX....."}
+	casos := []struct {
+		nombre  string
+		asiento int // Asiento que intenta mover; el turno es de X
+		dibujo  []string
+		columna int
+		err     error
+	}{
+		{"columna vacía", 0, columnas, 3, nil},
+		{"última celda libre de la columna", 0, columnas, 1, nil},
+		{"columna llena", 0, columnas, 0, ErrColumnaLlena},
+		{"columna negativa", 0, columnas, -1, ErrMovimientoInvalido},
+		{"columna fuera del tablero", 0, columnas, 7, ErrMovimientoInvalido},
+		{"ninguna columna en un tablero lleno", 0, conectaEmpate, 6, ErrColumnaLlena},
+		{"fuera de turno", 1, columnas, 3, ErrFueraDeTurno},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			err := (ConectaCuatro{}).ValidateMove(conectaCuatro(t, 0, caso.dibujo...), caso.asiento, &MovimientoConecta{Columna: caso.columna})
+			if !errors.Is(err, caso.err) {
+				t.Fatalf("error %v, se esperaba %v", err, caso.err)
+			}
+		})
+	}
+}
+
+func TestConectaCuatroApplyMove(t *testing.T) {
+	casos := []struct {
+		nombre  string
+		turno   int
+		dibujo  []string
+		columna int
+		despues []string
+	}{
+		{
+			"cae hasta abajo", 0,
+			[]string{".......", ".......", ".......", ".......", ".......", "......."}, 3,
+			[]string{".......", ".......", ".......", ".......", ".......", "...X..."},
+		},
+		{
+			"se apila sobre la anterior", 1,
+			[]string{".......", ".......", ".......", ".......", ".......", "...X..."}, 3,
+			[]string{".......", ".......", ".......", ".......", "...O...", "...X..."},
+		},
+		{
+			"llena la columna", 0,
+			[]string{".......", "OX.....", "XO.....", "OX.....", "XO.....", "OX....."}, 0,
+			[]string{"X......", "OX.....", "XO.....", "OX.....", "XO.....", "OX....."},
+		},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			juego := conectaCuatro(t, caso.turno, caso.dibujo...)
+			ConectaCuatro{}.ApplyMove(juego, caso.turno, &MovimientoConecta{Columna: caso.columna})
+			if juego.Tablero != tablero6x7(t, caso.despues...) {
+				t.Fatalf("tablero %v, se esperaba %v", juego.Tablero, caso.despues)
+			}
+			if juego.Turno != 1-caso.turno {
+				t.Fatalf("turno %d, se esperaba %d", juego.Turno, 1-caso.turno)
+			}
+		})
+	}
+}
+
+func TestConectaCuatroOutcome(t *testing.T) {
+	casos := []struct {
+		nombre    string
+		dibujo    []string
+		resultado Outcome
+	}{
+		{"vacío", []string{".......", ".......", ".......", ".......", ".......", "......."}, Outcome{Winner: NoWinner}},
+		{"gana X abajo", []string{".......", ".......", ".......", ".......", "OOO....", "XXXX..."}, Outcome{Finished: true, Winner: 0}},
+		{"gana O en vertical", []string{".......", ".......", "..O....", "..O....", "X.OX...", "XXOX..."}, Outcome{Finished: true, Winner: 1}},
+		{"gana X en diagonal", []string{".......", ".......", "...X...", "..XO...", ".XOO...", "XOOX..X"}, Outcome{Finished: true, Winner: 0}},
+		{"una columna llena no es empate", []string{"X......", "O......", "X......", "O......", "X......", "O......"}, Outcome{Winner: NoWinner}},
+		{"fila de arriba con un hueco", append([]string{"XXOOXX."}, conectaEmpate[1:]...), Outcome{Winner: NoWinner}},
+		{"tablero lleno sin línea es empate", conectaEmpate, Outcome{Finished: true, Winner: NoWinner}},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			if resultado := (ConectaCuatro{}).Outcome(conectaCuatro(t, 0, caso.dibujo...)); resultado != caso.resultado {
+				t.Fatalf("resultado %+v, se esperaba %+v", resultado, caso.resultado)
+			}
+		})
+	}
+}
+
+func TestConectaCuatroLegalMoves(t *testing.T) {
+	reglas := ConectaCuatro{}
+	juego := conectaCuatro(t, 0, "X.X.X.X", "O.O.O.O", "X.X.X.X", "O.O.O.O", "X.X.X.X", "O.O.O.O")
+	var columnas []int
+	for _, movimiento := range reglas.LegalMoves(juego) {
+		columnas = append(columnas, movimiento.(*MovimientoConecta).Columna)
+	}
+	if len(columnas) != 3 || columnas[0] != 1 || columnas[1] != 3 || columnas[2] != 5 {
+		t.Fatalf("columnas %v, se esperaban [1 3 5]", columnas)
+	}
+	if movimientos := reglas.LegalMoves(conectaCuatro(t, 0, conectaEmpate...)); len(movimientos) != 0 {
+		t.Fatalf("un tablero lleno no debería tener movimientos: %d", len(movimientos))
+	}
+}
@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"errors"
+	"juego/models"
+)
+
+// TipoCuatroEnRaya identifica al juego Cuatro en Raya
+const TipoCuatroEnRaya = "4_en_raya"
+
+var (
+	ErrOrigenFueraTablero = errors.New("Posición de origen fuera del tablero")
+	ErrOrigenSinFicha     = errors.New("La celda de origen no contiene tu ficha")
+)
+
+// MovimientoCuatroEnRaya coloca una ficha en el destino o, cuando el jugador ya
+// tiene cuatro fichas en el tablero, mueve la ficha de origen al destino
+type MovimientoCuatroEnRaya struct {
+	OrigenX  int `json:"origen_x,omitempty"` // Posición X de la ficha que quiere mover (opcional)
+	OrigenY  int `json:"origen_y,omitempty"` // Posición Y de la ficha que quiere mover (opcional)
+	DestinoX int `json:"destino_x"`          // Posición X del destino
+	DestinoY int `json:"destino_y"`          // Posición Y del destino
+}
+
+// CuatroEnRaya implementa las reglas de Cuatro en Raya sobre un tablero de 4x4
+type CuatroEnRaya struct{}
+
+func init() {
+	Register(CuatroEnRaya{})
+}
+
+func (CuatroEnRaya) Type() string { return TipoCuatroEnRaya }
+
+func (CuatroEnRaya) Seats() int { return 2 }
+
+func (CuatroEnRaya) NewState(id string, jugadores []models.Jugador) Game {
+	return &models.CuatroEnRaya{
+		Juego:     nuevoJuego(id, TipoCuatroEnRaya),
+		Jugadores: jugadores,
+		Turno:     0, // Comienza el jugador 0
+	}
+}
+
+func (CuatroEnRaya) NewMove() Move { return &MovimientoCuatroEnRaya{} }
+
+func (CuatroEnRaya) ValidateMove(g Game, seat int, m Move) error {
+	juego := g.(*models.CuatroEnRaya)
+	movimiento := m.(*MovimientoCuatroEnRaya)
+
+	if seat != juego.Turno {
+		return ErrFueraDeTurno
+	}
+	if !dentroDel4x4(movimiento.DestinoX, movimiento.DestinoY) {
+		return ErrDestinoFueraTablero
+	}
+	if juego.Tablero[movimiento.DestinoX][movimiento.DestinoY] != "" {
+		return ErrDestinoOcupado
+	}
+
+	// Con menos de 4 fichas el jugador está en la fase de colocación
+	ficha := fichas[juego.Turno]
+	if contarFichas(juego.Tablero, ficha) < 4 {
+		return nil
+	}
+	if !dentroDel4x4(movimiento.OrigenX, movimiento.OrigenY) {
+		return ErrOrigenFueraTablero
+	}
+	if juego.Tablero[movimiento.OrigenX][movimiento.OrigenY] != ficha {
+		return ErrOrigenSinFicha
+	}
+	return nil
+}
+
+func (CuatroEnRaya) ApplyMove(g Game, seat int, m Move) {
+	juego := g.(*models.CuatroEnRaya)
+	movimiento := m.(*MovimientoCuatroEnRaya)
+
+	ficha := fichas[juego.Turno]
+	if contarFichas(juego.Tablero, ficha) >= 4 {
+		juego.Tablero[movimiento.OrigenX][movimiento.OrigenY] = ""
+	}
+	juego.Tablero[movimiento.DestinoX][movimiento.DestinoY] = ficha
+	juego.Turno = 1 - juego.Turno
+}
+
+func (CuatroEnRaya) Outcome(g Game) Outcome {
+	juego := g.(*models.CuatroEnRaya)
+	celda := func(x, y int) string { return juego.Tablero[x][y] }
+
+	// Las fichas se siguen moviendo con el tablero lleno, así que no hay empate
+	if ganador := ganadorTablero(4, 4, celda); ganador != NoWinner {
+		return Outcome{Finished: true, Winner: ganador}
+	}
+	return Outcome{Winner: NoWinner}
+}
+
+func (CuatroEnRaya) CurrentPlayer(g Game) int {
+	return g.(*models.CuatroEnRaya).Turno
+}
+
+// contarFichas cuenta cuántas fichas tiene un jugador en el tablero
+func contarFichas(tablero [4][4]string, ficha string) int {
+	contador := 0
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			if tablero[i][j] == ficha {
+				contador++
+			}
+		}
+	}
+	return contador
+}
+
+// LegalMoves devuelve las celdas libres mientras el jugador coloca y, con sus
+// cuatro fichas en el tablero, cada ficha suya hacia cada celda libre
+func (CuatroEnRaya) LegalMoves(g Game) []Move {
+	juego := g.(*models.CuatroEnRaya)
+	ficha := fichas[juego.Turno]
+	colocando := contarFichas(juego.Tablero, ficha) < 4
+
+	var movimientos []Move
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 4; y++ {
+			if juego.Tablero[x][y] != "" {
+				continue
+			}
+			if colocando {
+				movimientos = append(movimientos, &MovimientoCuatroEnRaya{DestinoX: x, DestinoY: y})
+				continue
+			}
+			for origenX := 0; origenX < 4; origenX++ {
+				for origenY := 0; origenY < 4; origenY++ {
+					if juego.Tablero[origenX][origenY] == ficha {
+						movimientos = append(movimientos, &MovimientoCuatroEnRaya{OrigenX: origenX, OrigenY: origenY, DestinoX: x, DestinoY: y})
+					}
+				}
+			}
+		}
+	}
+	return movimientos
+}
+
+// Clone copia el tablero; los jugadores se comparten porque los movimientos no los cambian
+func (CuatroEnRaya) Clone(g Game) Game {
+	copia := *g.(*models.CuatroEnRaya)
+	return &copia
+}
@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"errors"
+	"juego/models"
+	"testing"
+)
+
+// cuatroEnRaya crea una partida de Cuatro en Raya con el tablero dibujado
+func cuatroEnRaya(t *testing.T, turno int, dibujo ...string) *models.CuatroEnRaya {
+	t.Helper()
+	juego := CuatroEnRaya{}.NewState("prueba", []models.Jugador{{ID: 1}, {ID: 2}}).(*models.CuatroEnRaya)
+	juego.Tablero = tablero4x4(t, dibujo...)
+	juego.Turno = turno
+	return juego
+}
+
+func TestCuatroEnRayaValidateMove(t *testing.T) {
+	// X ya tiene sus cuatro fichas en el tablero; O solo tres
+	colocadas := []string{"XO..", "XO..", "XO..", "X..."}
+	casos := []struct {
+		nombre     string
+		turno      int
+		asiento    int // Asiento que intenta mover
+		dibujo     []string
+		movimiento MovimientoCuatroEnRaya
+		err        error
+	}{
+		{"coloca en una celda libre", 0, 0, []string{"....", "....", "....", "...."}, MovimientoCuatroEnRaya{DestinoX: 2, DestinoY: 1}, nil},
+		{"destino fuera del tablero", 0, 0, []string{"....", "....", "....", "...."}, MovimientoCuatroEnRaya{DestinoX: 4, DestinoY: 0}, ErrDestinoFueraTablero},
+		{"destino negativo", 0, 0, []string{"....", "....", "....", "...."}, MovimientoCuatroEnRaya{DestinoX: 0, DestinoY: -1}, ErrDestinoFueraTablero},
+		{"destino ocupado", 1, 1, []string{"X...", "....", "....", "...."}, MovimientoCuatroEnRaya{DestinoX: 0, DestinoY: 0}, ErrDestinoOcupado},
+		{"con tres fichas aún coloca sin origen", 1, 1, colocadas, MovimientoCuatroEnRaya{DestinoX: 3, DestinoY: 3}, nil},
+		{"con cuatro fichas mueve una suya", 0, 0, colocadas, MovimientoCuatroEnRaya{OrigenX: 3, OrigenY: 0, DestinoX: 3, DestinoY: 3}, nil},
+		{"con cuatro fichas el origen debe estar en el tablero", 0, 0, colocadas, MovimientoCuatroEnRaya{OrigenX: -1, OrigenY: 0, DestinoX: 3, DestinoY: 3}, ErrOrigenFueraTablero},
+		{"con cuatro fichas el origen no puede estar libre", 0, 0, colocadas, MovimientoCuatroEnRaya{OrigenX: 2, OrigenY: 2, DestinoX: 3, DestinoY: 3}, ErrOrigenSinFicha},
+		{"con cuatro fichas no se mueve la ficha del rival", 0, 0, colocadas, MovimientoCuatroEnRaya{OrigenX: 0, OrigenY: 1, DestinoX: 3, DestinoY: 3}, ErrOrigenSinFicha},
+		{"fuera de turno", 0, 1, []string{"....", "....", "....", "...."}, MovimientoCuatroEnRaya{DestinoX: 2, DestinoY: 1}, ErrFueraDeTurno},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			juego := cuatroEnRaya(t, caso.turno, caso.dibujo...)
+			movimiento := caso.movimiento
+			if err := (CuatroEnRaya{}).ValidateMove(juego, caso.asiento, &movimiento); !errors.Is(err, caso.err) {
+				t.Fatalf("error %v, se esperaba %v", err, caso.err)
+			}
+		})
+	}
+}
+
+func TestCuatroEnRayaApplyMove(t *testing.T) {
+	casos := []struct {
+		nombre     string
+		turno      int
+		dibujo     []string
+		movimiento MovimientoCuatroEnRaya
+		despues    []string
+	}{
+		{
+			"coloca la ficha del turno", 1,
+			[]string{"X...", "....", "....", "...."},
+			MovimientoCuatroEnRaya{DestinoX: 1, DestinoY: 1},
+			[]string{"X...", ".O..", "....", "...."},
+		},
+		{
+			"la cuarta ficha todavía se coloca", 0,
+			[]string{"XO..", "XO..", "XO..", "...."},
+			MovimientoCuatroEnRaya{DestinoX: 3, DestinoY: 3},
+			[]string{"XO..", "XO..", "XO..", "...X"},
+		},
+		{
+			"con cuatro fichas la ficha se mueve", 0,
+			[]string{"XO..", "XO..", "XO..", "X..."},
+			MovimientoCuatroEnRaya{OrigenX: 0, OrigenY: 0, DestinoX: 3, DestinoY: 3},
+			[]string{".O..", "XO..", "XO..", "X..X"},
+		},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			juego := cuatroEnRaya(t, caso.turno, caso.dibujo...)
+			movimiento := caso.movimiento
+			CuatroEnRaya{}.ApplyMove(juego, caso.turno, &movimiento)
+			if juego.Tablero != tablero4x4(t, caso.despues...) {
+				t.Fatalf("tablero %v, se esperaba %v", juego.Tablero, caso.despues)
+			}
+			if juego.Turno != 1-caso.turno {
+				t.Fatalf("turno %d, se esperaba %d", juego.Turno, 1-caso.turno)
+			}
+		})
+	}
+}
+
+func TestCuatroEnRayaOutcome(t *testing.T) {
+	casos := []struct {
+		nombre    string
+		dibujo    []string
+		resultado Outcome
+	}{
+		{"vacío", []string{"....", "....", "....", "...."}, Outcome{Winner: NoWinner}},
+		{"en curso", []string{"XO..", "XO..", "XO..", "...."}, Outcome{Winner: NoWinner}},
+		{"gana X en columna", []string{"XO..", "XO..", "XO..", "X..O"}, Outcome{Finished: true, Winner: 0}},
+		{"gana O en diagonal", []string{"O..X", "XO..", "X.O.", "X..O"}, Outcome{Finished: true, Winner: 1}},
+		{"ocho fichas sin línea sigue en curso", []string{"XO..", "OX..", "XO..", "OX.."}, Outcome{Winner: NoWinner}},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			if resultado := (CuatroEnRaya{}).Outcome(cuatroEnRaya(t, 0, caso.dibujo...)); resultado != caso.resultado {
+				t.Fatalf("resultado %+v, se esperaba %+v", resultado, caso.resultado)
+			}
+		})
+	}
+}
+
+func TestCuatroEnRayaPasaDeColocarAMover(t *testing.T) {
+	// Partida completa por Play: cuatro colocaciones de cada uno y después movimientos
+	reglas := CuatroEnRaya{}
+	juego := reglas.NewState("prueba", []models.Jugador{{ID: 1}, {ID: 2}})
+	juego.Comun().Estado = models.EstadoEnProgreso
+
+	colocaciones := [][2]int{{0, 0}, {0, 1}, {1, 1}, {1, 0}, {2, 3}, {3, 2}, {3, 3}, {2, 2}}
+	for i, celda := range colocaciones {
+		if _, err := Play(reglas, juego, i%2, &MovimientoCuatroEnRaya{DestinoX: celda[0], DestinoY: celda[1]}); err != nil {
+			t.Fatalf("colocación %d: %v", i, err)
+		}
+	}
+
+	// Sin origen, X intenta colocar una quinta ficha: el origen por defecto (0,0) es suyo, así que la mueve
+	if _, err := Play(reglas, juego, 0, &MovimientoCuatroEnRaya{DestinoX: 0, DestinoY: 3}); err != nil {
+		t.Fatal(err)
+	}
+	tablero := juego.(*models.CuatroEnRaya).Tablero
+	if tablero[0][0] != "" || tablero[0][3] != "X" || contarFichas(tablero, "X") != 4 {
+		t.Fatalf("la ficha de X no se ha movido: %v", tablero)
+	}
+
+	// O tampoco puede sumar fichas, y no puede mover desde una celda vacía
+	if _, err := Play(reglas, juego, 1, &MovimientoCuatroEnRaya{OrigenX: 0, OrigenY: 0, DestinoX: 3, DestinoY: 0}); !errors.Is(err, ErrOrigenSinFicha) {
+		t.Fatalf("error %v, se esperaba %v", err, ErrOrigenSinFicha)
+	}
+	if _, err := Play(reglas, juego, 1, &MovimientoCuatroEnRaya{OrigenX: 0, OrigenY: 1, DestinoX: 3, DestinoY: 0}); err != nil {
+		t.Fatal(err)
+	}
+	if contarFichas(juego.(*models.CuatroEnRaya).Tablero, "O") != 4 {
+		t.Fatalf("O debería seguir con cuatro fichas: %v", juego.(*models.CuatroEnRaya).Tablero)
+	}
+}
@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"errors"
+	"juego/models"
+)
+
+// TipoDesdeElBorde identifica a la variante de Cuatro en Raya desde el borde
+const TipoDesdeElBorde = "4_en_raya_desde_borde"
+
+var ErrBordeExterior = errors.New("Debes colocar la ficha en el borde exterior primero")
+
+// MovimientoDesdeBorde es la celda en la que se coloca la ficha
+type MovimientoDesdeBorde struct {
+	DestinoX int `json:"destino_x"`
+	DestinoY int `json:"destino_y"`
+}
+
+// DesdeElBorde implementa Cuatro en Raya en 4x4 donde solo se puede colocar en
+// las celdas interiores cuando el anillo exterior está completo
+type DesdeElBorde struct{}
+
+func init() {
+	Register(DesdeElBorde{})
+}
+
+func (DesdeElBorde) Type() string { return TipoDesdeElBorde }
+
+func (DesdeElBorde) Seats() int { return 2 }
+
+func (DesdeElBorde) NewState(id string, jugadores []models.Jugador) Game {
+	return &models.CuatroEnRaya{
+		Juego:     nuevoJuego(id, TipoDesdeElBorde),
+		Jugadores: jugadores,
+		Turno:     0, // Comienza el jugador 0
+	}
+}
+
+func (DesdeElBorde) NewMove() Move { return &MovimientoDesdeBorde{} }
+
+func (DesdeElBorde) ValidateMove(g Game, seat int, m Move) error {
+	juego := g.(*models.CuatroEnRaya)
+	movimiento := m.(*MovimientoDesdeBorde)
+
+	if seat != juego.Turno {
+		return ErrFueraDeTurno
+	}
+	if !dentroDel4x4(movimiento.DestinoX, movimiento.DestinoY) {
+		return ErrDestinoFueraTablero
+	}
+	// Si el borde exterior no está lleno, solo se pueden colocar fichas allí
+	if !anilloExteriorLleno(juego.Tablero) && !esAnilloExterior(movimiento.DestinoX, movimiento.DestinoY) {
+		return ErrBordeExterior
+	}
+	if juego.Tablero[movimiento.DestinoX][movimiento.DestinoY] != "" {
+		return ErrDestinoOcupado
+	}
+	return nil
+}
+
+func (DesdeElBorde) ApplyMove(g Game, seat int, m Move) {
+	juego := g.(*models.CuatroEnRaya)
+	movimiento := m.(*MovimientoDesdeBorde)
+
+	juego.Tablero[movimiento.DestinoX][movimiento.DestinoY] = fichas[juego.Turno]
+	juego.Turno = 1 - juego.Turno
+}
+
+func (DesdeElBorde) Outcome(g Game) Outcome {
+	juego := g.(*models.CuatroEnRaya)
+	celda := func(x, y int) string { return juego.Tablero[x][y] }
+
+	if ganador := ganadorTablero(4, 4, celda); ganador != NoWinner {
+		return Outcome{Finished: true, Winner: ganador}
+	}
+	if tableroLleno4x4(juego.Tablero) {
+		return Outcome{Finished: true, Winner: NoWinner}
+	}
+	return Outcome{Winner: NoWinner}
+}
+
+func (DesdeElBorde) CurrentPlayer(g Game) int {
+	return g.(*models.CuatroEnRaya).Turno
+}
+
+// esAnilloExterior indica si la celda pertenece al borde del tablero
+func esAnilloExterior(x, y int) bool {
+	return x == 0 || x == 3 || y == 0 || y == 3
+}
+
+// anilloExteriorLleno verifica si todas las celdas del borde están ocupadas
+func anilloExteriorLleno(tablero [4][4]string) bool {
+	for i := 0; i < 4; i++ {
+		if tablero[0][i] == "" || tablero[3][i] == "" || tablero[i][0] == "" || tablero[i][3] == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// LegalMoves devuelve las celdas libres del borde exterior y, cuando está lleno,
+// las del interior
+func (DesdeElBorde) LegalMoves(g Game) []Move {
+	juego := g.(*models.CuatroEnRaya)
+	soloBorde := !anilloExteriorLleno(juego.Tablero)
+
+	var movimientos []Move
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 4; y++ {
+			if juego.Tablero[x][y] != "" || (soloBorde && !esAnilloExterior(x, y)) {
+				continue
+			}
+			movimientos = append(movimientos, &MovimientoDesdeBorde{DestinoX: x, DestinoY: y})
+		}
+	}
+	return movimientos
+}
+
+// Clone copia el tablero; los jugadores se comparten porque los movimientos no los cambian
+func (DesdeElBorde) Clone(g Game) Game {
+	copia := *g.(*models.CuatroEnRaya)
+	return &copia
+}
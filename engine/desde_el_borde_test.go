@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"errors"
+	"juego/models"
+	"testing"
+)
+
+// desdeElBorde crea una partida de Desde el Borde con el tablero dibujado
+func desdeElBorde(t *testing.T, turno int, dibujo ...string) *models.CuatroEnRaya {
+	t.Helper()
+	juego := DesdeElBorde{}.NewState("prueba", []models.Jugador{{ID: 1}, {ID: 2}}).(*models.CuatroEnRaya)
+	juego.Tablero = tablero4x4(t, dibujo...)
+	juego.Turno = turno
+	return juego
+}
+
+// anilloLleno tiene el borde exterior completo sin cuatro en línea y el interior libre
+var anilloLleno = []string{"XOXO", "O..X", "X..O", "OXOX"}
+
+func TestDesdeElBordeValidateMove(t *testing.T) {
+	vacio := []string{"....", "....", "....", "...."}
+	casi := []string{"XOXO", "O..X", "X..O", "OXO."}
+	casos := []struct {
+		nombre     string
+		asiento    int // Asiento que intenta mover; el turno es de X
+		dibujo     []string
+		movimiento MovimientoDesdeBorde
+		err        error
+	}{
+		{"esquina con el tablero vacío", 0, vacio, MovimientoDesdeBorde{DestinoX: 0, DestinoY: 0}, nil},
+		{"lateral con el tablero vacío", 0, vacio, MovimientoDesdeBorde{DestinoX: 2, DestinoY: 3}, nil},
+		{"interior con el tablero vacío", 0, vacio, MovimientoDesdeBorde{DestinoX: 1, DestinoY: 1}, ErrBordeExterior},
+		{"interior con una celda del borde libre", 0, casi, MovimientoDesdeBorde{DestinoX: 2, DestinoY: 2}, ErrBordeExterior},
+		{"última celda del borde", 0, casi, MovimientoDesdeBorde{DestinoX: 3, DestinoY: 3}, nil},
+		{"interior con el borde lleno", 0, anilloLleno, MovimientoDesdeBorde{DestinoX: 1, DestinoY: 2}, nil},
+		{"borde ocupado", 0, anilloLleno, MovimientoDesdeBorde{DestinoX: 0, DestinoY: 1}, ErrDestinoOcupado},
+		{"fuera del tablero", 0, vacio, MovimientoDesdeBorde{DestinoX: -1, DestinoY: 0}, ErrDestinoFueraTablero},
+		{"fuera de turno", 1, vacio, MovimientoDesdeBorde{DestinoX: 0, DestinoY: 0}, ErrFueraDeTurno},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			movimiento := caso.movimiento
+			if err := (DesdeElBorde{}).ValidateMove(desdeElBorde(t, 0, caso.dibujo...), caso.asiento, &movimiento); !errors.Is(err, caso.err) {
+				t.Fatalf("error %v, se esperaba %v", err, caso.err)
+			}
+		})
+	}
+}
+
+func TestDesdeElBordeApplyMove(t *testing.T) {
+	casos := []struct {
+		nombre     string
+		turno      int
+		dibujo     []string
+		movimiento MovimientoDesdeBorde
+		despues    []string
+	}{
+		{"X en el borde", 0, []string{"....", "....", "....", "...."}, MovimientoDesdeBorde{DestinoX: 3, DestinoY: 1}, []string{"....", "....", "....", ".X.."}},
+		{"O en el interior", 1, anilloLleno, MovimientoDesdeBorde{DestinoX: 2, DestinoY: 1}, []string{"XOXO", "O..X", "XO.O", "OXOX"}},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			juego := desdeElBorde(t, caso.turno, caso.dibujo...)
+			movimiento := caso.movimiento
+			DesdeElBorde{}.ApplyMove(juego, caso.turno, &movimiento)
+			if juego.Tablero != tablero4x4(t, caso.despues...) {
+				t.Fatalf("tablero %v, se esperaba %v", juego.Tablero, caso.despues)
+			}
+			if juego.Turno != 1-caso.turno {
+				t.Fatalf("turno %d, se esperaba %d", juego.Turno, 1-caso.turno)
+			}
+		})
+	}
+}
+
+func TestDesdeElBordeOutcome(t *testing.T) {
+	casos := []struct {
+		nombre    string
+		dibujo    []string
+		resultado Outcome
+	}{
+		{"vacío", []string{"....", "....", "....", "...."}, Outcome{Winner: NoWinner}},
+		{"borde lleno sin línea", anilloLleno, Outcome{Winner: NoWinner}},
+		{"gana X en la fila de arriba", []string{"XXXX", "O..O", "O...", "...."}, Outcome{Finished: true, Winner: 0}},
+		{"gana O en la diagonal", []string{"OXXO", "XOXX", "X.OO", "XOXO"}, Outcome{Finished: true, Winner: 1}},
+		{"tablero lleno sin línea es empate", []string{"XOXO", "OOXX", "XXOO", "OXOX"}, Outcome{Finished: true, Winner: NoWinner}},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			if resultado := (DesdeElBorde{}).Outcome(desdeElBorde(t, 0, caso.dibujo...)); resultado != caso.resultado {
+				t.Fatalf("resultado %+v, se esperaba %+v", resultado, caso.resultado)
+			}
+		})
+	}
+}
+
+func TestDesdeElBordeLegalMoves(t *testing.T) {
+	casos := []struct {
+		nombre string
+		dibujo []string
+		total  int
+	}{
+		{"solo el borde con el tablero vacío", []string{"....", "....", "....", "...."}, 12},
+		{"el interior cuando el borde está lleno", anilloLleno, 4},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			reglas := DesdeElBorde{}
+			juego := desdeElBorde(t, 0, caso.dibujo...)
+			movimientos := reglas.LegalMoves(juego)
+			if len(movimientos) != caso.total {
+				t.Fatalf("%d movimientos, se esperaban %d", len(movimientos), caso.total)
+			}
+			for _, movimiento := range movimientos {
+				if err := reglas.ValidateMove(juego, 0, movimiento); err != nil {
+					t.Fatalf("movimiento legal %+v rechazado: %v", movimiento, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+// Package engine define las reglas comunes a todos los juegos. Cada juego solo
+// tiene que implementar Rules y registrarse; la capa HTTP es la misma para todos.
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"juego/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// AnySeat indica que cualquier jugador puede mover (juegos sin turnos)
+	AnySeat = -1
+	// NoWinner indica que la partida no tiene ganador (en curso o empate)
+	NoWinner = -1
+)
+
+var (
+	ErrJuegoTerminado      = errors.New("El juego ya ha terminado")
+	ErrEsperandoJugadores  = errors.New("La partida aún tiene asientos libres")
+	ErrMovimientoInvalido  = errors.New("Movimiento inválido")
+	ErrDestinoFueraTablero = errors.New("Posición de destino fuera del tablero")
+	ErrDestinoOcupado      = errors.New("La celda de destino ya está ocupada")
+	ErrFueraDeTurno        = errors.New("No es el turno de ese asiento")
+)
+
+// Game es el estado concreto de una partida (models.ConectaCuatro, models.CuatroEnRaya...)
+type Game interface {
+	Comun() *models.Juego
+	Participantes() []models.Jugador
+	// Sentar pone a un jugador en un asiento libre (los libres tienen ID 0)
+	Sentar(asiento int, jugador models.Jugador)
+}
+
+// Move es el cuerpo de un movimiento; cada juego define su propia estructura
+type Move interface{}
+
+// Outcome es el resultado de una partida tras un movimiento
+type Outcome struct {
+	Finished bool // La partida ha terminado
+	Winner   int  // Asiento del ganador, NoWinner si empate o en curso
+}
+
+// Rules son las reglas de un tipo de juego
+type Rules interface {
+	// Type devuelve el identificador del juego (p. ej. "conecta_cuatro")
+	Type() string
+	// Seats devuelve el número de jugadores que necesita la partida
+	Seats() int
+	// NewState crea una partida nueva con el tablero vacío
+	NewState(id string, jugadores []models.Jugador) Game
+	// NewMove devuelve un movimiento vacío sobre el que decodificar la petición
+	NewMove() Move
+	// ValidateMove comprueba que el jugador sentado en seat puede hacer el movimiento
+	ValidateMove(g Game, seat int, m Move) error
+	// ApplyMove aplica un movimiento ya validado y avanza el turno
+	ApplyMove(g Game, seat int, m Move)
+	// Outcome indica si la partida ha terminado y quién ha ganado
+	Outcome(g Game) Outcome
+	// CurrentPlayer devuelve el asiento que tiene el turno, o AnySeat
+	CurrentPlayer(g Game) int
+}
+
+// Restarter lo implementan los juegos que se pueden reiniciar sin crear otra partida
+type Restarter interface {
+	Restart(g Game)
+}
+
+// MoveGenerator lo implementan los juegos que saben listar los movimientos
+// legales del jugador que tiene el turno
+type MoveGenerator interface {
+	LegalMoves(g Game) []Move
+}
+
+// Cloner lo implementan los juegos que pueden copiar una partida para probar
+// movimientos sobre la copia sin tocar la original
+type Cloner interface {
+	Clone(g Game) Game
+}
+
+// Searchable son las reglas sobre las que se pueden buscar movimientos: además
+// de aplicar un movimiento y saber si la partida ha acabado y quién ha ganado,
+// listan los movimientos legales y copian la partida
+type Searchable interface {
+	Rules
+	MoveGenerator
+	Cloner
+}
+
+var (
+	registro      = make(map[string]Rules)
+	registroMutex sync.RWMutex
+)
+
+// Register añade un tipo de juego al registro; lo llaman los juegos desde init
+func Register(r Rules) {
+	registroMutex.Lock()
+	defer registroMutex.Unlock()
+	if _, existe := registro[r.Type()]; existe {
+		panic("engine: juego registrado dos veces: " + r.Type())
+	}
+	registro[r.Type()] = r
+}
+
+// Lookup devuelve las reglas de un tipo de juego
+func Lookup(tipo string) (Rules, bool) {
+	registroMutex.RLock()
+	defer registroMutex.RUnlock()
+	r, existe := registro[tipo]
+	return r, existe
+}
+
+// Types devuelve los tipos de juego registrados ordenados alfabéticamente
+func Types() []string {
+	registroMutex.RLock()
+	defer registroMutex.RUnlock()
+	tipos := make([]string, 0, len(registro))
+	for tipo := range registro {
+		tipos = append(tipos, tipo)
+	}
+	sort.Strings(tipos)
+	return tipos
+}
+
+// Play valida y aplica un movimiento, y actualiza el estado común de la partida
+func Play(r Rules, g Game, seat int, m Move) (Outcome, error) {
+	comun := g.Comun()
+	if comun.Estado == models.EstadoEsperando {
+		return Outcome{}, ErrEsperandoJugadores
+	}
+	if comun.Estado != models.EstadoEnProgreso {
+		return Outcome{}, ErrJuegoTerminado
+	}
+	if err := r.ValidateMove(g, seat, m); err != nil {
+		return Outcome{}, err
+	}
+
+	r.ApplyMove(g, seat, m)
+	comun.Actualizado = time.Now()
+
+	resultado := r.Outcome(g)
+	if resultado.Finished {
+		if resultado.Winner == NoWinner {
+			comun.Estado = models.EstadoEmpate
+		} else {
+			comun.Estado = models.EstadoTerminado
+			ganador := g.Participantes()[resultado.Winner]
+			comun.Ganador = &ganador
+		}
+	}
+	return resultado, nil
+}
+
+// Decode reconstruye una partida a partir de su estado guardado en JSON
+func Decode(r Rules, datos []byte) (Game, error) {
+	juego := r.NewState("", nil)
+	if err := json.Unmarshal(datos, juego); err != nil {
+		return nil, err
+	}
+	return juego, nil
+}
+
+// HashState calcula el SHA-256 del estado de la partida sin contar las fechas,
+// de modo que una repetición de los mismos movimientos da el mismo hash
+func HashState(g Game) (string, error) {
+	comun := g.Comun()
+	creado, actualizado := comun.CreadoEn, comun.Actualizado
+	comun.CreadoEn, comun.Actualizado = time.Time{}, time.Time{}
+	datos, err := json.Marshal(g)
+	comun.CreadoEn, comun.Actualizado = creado, actualizado
+	if err != nil {
+		return "", err
+	}
+	suma := sha256.Sum256(datos)
+	return hex.EncodeToString(suma[:]), nil
+}
+
+// nuevoJuego rellena los campos comunes de una partida recién creada
+func nuevoJuego(id, tipo string) models.Juego {
+	return models.Juego{
+		ID:          id,
+		TipoJuego:   tipo,
+		Estado:      models.EstadoEnProgreso,
+		CreadoEn:    time.Now(),
+		Actualizado: time.Now(),
+	}
+}
@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"juego/models"
+	"math/rand"
+	"time"
+)
+
+// TipoPasaBolas identifica al juego Pasa Bolas
+const TipoPasaBolas = "pasa_bolas"
+
+var (
+	ErrJugadoresNoValidos = errors.New("Jugadores no válidos o eliminados")
+	ErrLanzamientoAjeno   = errors.New("Solo puedes lanzar tus propias bolas")
+)
+
+// posicionesPasaBolas asigna a cada asiento un lado de la mesa
+var posicionesPasaBolas = []string{"arriba", "derecha", "abajo", "izquierda"}
+
+// MovimientoPasaBolas lanza una bola de un jugador a otro
+type MovimientoPasaBolas struct {
+	DesdeID uint `json:"desde_id"` // ID del jugador que lanza la bola
+	HaciaID uint `json:"hacia_id"` // ID del jugador que recibe la bola
+}
+
+// PasaBolas implementa las reglas de Pasa Bolas para cuatro jugadores sin turnos
+type PasaBolas struct{}
+
+func init() {
+	Register(PasaBolas{})
+}
+
+func (PasaBolas) Type() string { return TipoPasaBolas }
+
+func (PasaBolas) Seats() int { return 4 }
+
+func (PasaBolas) NewState(id string, jugadores []models.Jugador) Game {
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	// Inicializar las bolas para cada jugador
+	var jugadoresPasaBolas []models.JugadorPasaBolas
+	for i, jugador := range jugadores {
+		jugadoresPasaBolas = append(jugadoresPasaBolas, models.JugadorPasaBolas{
+			Jugador:   jugador,
+			Bolas:     nuevasBolas(random, jugador.ID),
+			Eliminado: false,
+			Posicion:  posicionesPasaBolas[i],
+		})
+	}
+
+	return &models.PasaBolas{
+		Juego:        nuevoJuego(id, TipoPasaBolas),
+		Jugadores:    jugadoresPasaBolas,
+		Ciclos:       0,
+		Temporizador: 60, // Ciclo de 60 segundos (predeterminado)
+	}
+}
+
+func (PasaBolas) NewMove() Move { return &MovimientoPasaBolas{} }
+
+func (PasaBolas) ValidateMove(g Game, seat int, m Move) error {
+	juego := g.(*models.PasaBolas)
+	movimiento := m.(*MovimientoPasaBolas)
+
+	desde, hacia := buscarLanzamiento(juego, movimiento)
+	if desde < 0 || hacia < 0 {
+		return ErrJugadoresNoValidos
+	}
+	if seat != AnySeat && seat != desde {
+		return ErrLanzamientoAjeno
+	}
+	return nil
+}
+
+func (PasaBolas) ApplyMove(g Game, seat int, m Move) {
+	juego := g.(*models.PasaBolas)
+	desde, hacia := buscarLanzamiento(juego, m.(*MovimientoPasaBolas))
+
+	// Se lanza siempre la primera bola del jugador, si le queda alguna
+	desdeJugador := &juego.Jugadores[desde]
+	haciaJugador := &juego.Jugadores[hacia]
+	if len(desdeJugador.Bolas) > 0 {
+		haciaJugador.Bolas = append(haciaJugador.Bolas, desdeJugador.Bolas[0])
+		desdeJugador.Bolas = desdeJugador.Bolas[1:]
+	}
+}
+
+// Outcome nunca da la partida por terminada: Pasa Bolas se termina a mano
+func (PasaBolas) Outcome(g Game) Outcome {
+	return Outcome{Winner: NoWinner}
+}
+
+func (PasaBolas) CurrentPlayer(g Game) int { return AnySeat }
+
+// Restart reparte bolas nuevas a todos los jugadores y los vuelve a meter en juego
+func (PasaBolas) Restart(g Game) {
+	juego := g.(*models.PasaBolas)
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for i := range juego.Jugadores {
+		juego.Jugadores[i].Bolas = nuevasBolas(random, juego.Jugadores[i].Jugador.ID)
+		juego.Jugadores[i].Eliminado = false
+	}
+	juego.Estado = models.EstadoEnProgreso
+	juego.Ganador = nil
+	juego.Actualizado = time.Now()
+}
+
+// buscarLanzamiento devuelve los asientos de quien lanza y quien recibe, o -1
+// si alguno no está en la partida o ya fue eliminado
+func buscarLanzamiento(juego *models.PasaBolas, movimiento *MovimientoPasaBolas) (int, int) {
+	desde, hacia := -1, -1
+	for i, jugador := range juego.Jugadores {
+		if jugador.Eliminado {
+			continue
+		}
+		if jugador.Jugador.ID == movimiento.DesdeID {
+			desde = i
+		}
+		if jugador.Jugador.ID == movimiento.HaciaID {
+			hacia = i
+		}
+	}
+	return desde, hacia
+}
+
+// nuevasBolas crea las 10 bolas iniciales de un jugador en posiciones aleatorias
+func nuevasBolas(random *rand.Rand, playerID uint) []models.Bola {
+	var bolas []models.Bola
+	for j := 0; j < 10; j++ {
+		bolas = append(bolas, models.Bola{
+			X:        150 + random.Float64()*100,
+			Y:        150 + random.Float64()*100,
+			VX:       0,
+			VY:       0,
+			Color:    fmt.Sprintf("#%06X", random.Intn(0xFFFFFF)),
+			PlayerID: playerID,
+		})
+	}
+	return bolas
+}
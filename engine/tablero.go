@@ -0,0 +1,65 @@
+package engine
+
+// fichas asigna una ficha a cada asiento en los juegos de tablero de dos jugadores
+var fichas = [2]string{"X", "O"}
+
+// Ficha devuelve la ficha con la que juega un asiento en los juegos de tablero
+func Ficha(asiento int) string {
+	return fichas[asiento]
+}
+
+// direcciones en las que se puede formar una línea: horizontal, vertical y diagonales
+var direcciones = [][2]int{{0, 1}, {1, 0}, {1, 1}, {-1, 1}}
+
+// cuatroEnLinea revisa si hay cuatro fichas iguales seguidas en cualquier dirección
+func cuatroEnLinea(filas, columnas int, celda func(fila, columna int) string, ficha string) bool {
+	for i := 0; i < filas; i++ {
+		for j := 0; j < columnas; j++ {
+			if celda(i, j) != ficha {
+				continue
+			}
+			for _, dir := range direcciones {
+				conteo := 1
+				for paso := 1; paso < 4; paso++ {
+					nuevaFila := i + paso*dir[0]
+					nuevaColumna := j + paso*dir[1]
+					if nuevaFila < 0 || nuevaFila >= filas || nuevaColumna < 0 || nuevaColumna >= columnas || celda(nuevaFila, nuevaColumna) != ficha {
+						break
+					}
+					conteo++
+				}
+				if conteo >= 4 {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// ganadorTablero devuelve el asiento cuya ficha forma cuatro en línea, o NoWinner
+func ganadorTablero(filas, columnas int, celda func(fila, columna int) string) int {
+	for asiento, ficha := range fichas {
+		if cuatroEnLinea(filas, columnas, celda, ficha) {
+			return asiento
+		}
+	}
+	return NoWinner
+}
+
+// tableroLleno4x4 verifica si no queda ninguna celda libre en un tablero de 4x4
+func tableroLleno4x4(tablero [4][4]string) bool {
+	for _, fila := range tablero {
+		for _, celda := range fila {
+			if celda == "" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// dentroDel4x4 comprueba que una posición está dentro de un tablero de 4x4
+func dentroDel4x4(x, y int) bool {
+	return x >= 0 && x < 4 && y >= 0 && y < 4
+}
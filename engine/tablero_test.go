@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"testing"
+)
+
+// celdas convierte un dibujo del tablero (una cadena por fila, '.' para las
+// celdas libres) en la función de acceso que usan ganadorTablero y compañía
+func celdas(t *testing.T, filas, columnas int, dibujo []string) func(fila, columna int) string {
+	t.Helper()
+	if len(dibujo) != filas {
+		t.Fatalf("el dibujo tiene %d filas, se esperaban %d", len(dibujo), filas)
+	}
+	for _, fila := range dibujo {
+		if len(fila) != columnas {
+			t.Fatalf("la fila %q no tiene %d columnas", fila, columnas)
+		}
+	}
+	return func(fila, columna int) string {
+		if dibujo[fila][columna] == '.' {
+			return ""
+		}
+		return string(dibujo[fila][columna])
+	}
+}
+
+// tablero4x4 dibuja un tablero de Cuatro en Raya; la primera coordenada es la fila
+func tablero4x4(t *testing.T, dibujo ...string) [4][4]string {
+	t.Helper()
+	celda := celdas(t, 4, 4, dibujo)
+	var tablero [4][4]string
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 4; y++ {
+			tablero[x][y] = celda(x, y)
+		}
+	}
+	return tablero
+}
+
+// tablero6x7 dibuja un tablero de Conecta Cuatro con la fila 5 abajo
+func tablero6x7(t *testing.T, dibujo ...string) [6][7]string {
+	t.Helper()
+	celda := celdas(t, 6, 7, dibujo)
+	var tablero [6][7]string
+	for fila := 0; fila < 6; fila++ {
+		for columna := 0; columna < 7; columna++ {
+			tablero[fila][columna] = celda(fila, columna)
+		}
+	}
+	return tablero
+}
+
+func TestGanadorTablero(t *testing.T) {
+	casos := []struct {
+		nombre  string
+		dibujo  []string
+		ganador int
+	}{
+		{"vacío", []string{"....", "....", "....", "...."}, NoWinner},
+		{"horizontal", []string{"....", "XXXX", "O.O.", "O..."}, 0},
+		{"vertical", []string{"X..O", "X..O", "...O", "X.XO"}, 1},
+		{"diagonal", []string{"X...", "OX..", "O.X.", "O..X"}, 0},
+		{"diagonal inversa", []string{"...O", "..O.", ".OX.", "OXX."}, 1},
+		{"tres en línea", []string{"XXX.", "OOO.", "....", "...."}, NoWinner},
+		{"línea cortada por el rival", []string{"XXOX", "O..O", "...O", "...X"}, NoWinner},
+		{"tablero lleno sin línea", []string{"XOXO", "OOXX", "XXOO", "OXOX"}, NoWinner},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			if ganador := ganadorTablero(4, 4, celdas(t, 4, 4, caso.dibujo)); ganador != caso.ganador {
+				t.Fatalf("ganador %d, se esperaba %d", ganador, caso.ganador)
+			}
+		})
+	}
+}
+
+func TestGanadorTableroRectangular(t *testing.T) {
+	// En 6x7 las líneas pueden acabar en la última columna y la última fila
+	casos := []struct {
+		nombre  string
+		dibujo  []string
+		ganador int
+	}{
+		{"horizontal a la derecha", []string{".......", ".......", ".......", ".......", "OOO....", "...XXXX"}, 0},
+		{"vertical en la última columna", []string{".......", ".......", "......O", "......O", "X.....O", "XX....O"}, 1},
+		{"diagonal que llega arriba", []string{"......X", ".....XO", "....XOO", "...XOOX", ".......", "......."}, 0},
+		{"sin línea", []string{".......", ".......", ".......", "...O...", "..XX...", ".OXXO.."}, NoWinner},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			if ganador := ganadorTablero(6, 7, celdas(t, 6, 7, caso.dibujo)); ganador != caso.ganador {
+				t.Fatalf("ganador %d, se esperaba %d", ganador, caso.ganador)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"juego/middleware"
+	"juego/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tamaño de página del historial del chat
+const (
+	mensajesPorPagina    = 50
+	maxMensajesPorPagina = 200
+)
+
+// ChatHandler es el chat de las partidas: enviar mensajes, leer el historial y silenciar
+type ChatHandler struct {
+	ChatService *services.ChatService
+}
+
+func NewChatHandler(chatService *services.ChatService) *ChatHandler {
+	return &ChatHandler{ChatService: chatService}
+}
+
+// Enviar — Publica un mensaje del jugador autenticado ({"texto": "...", "canal": "jugadores|espectadores"})
+func (h *ChatHandler) Enviar(c *gin.Context) {
+	var datos struct {
+		Texto string `json:"texto" binding:"required"`
+		Canal string `json:"canal"`
+	}
+	if err := c.ShouldBindJSON(&datos); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	mensaje, err := h.ChatService.Enviar(c.Param("type"), c.Param("id"), middleware.JugadorActual(c), datos.Canal, datos.Texto)
+	if err != nil {
+		responderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"mensaje": mensaje})
+}
+
+// Historial — Mensajes de un canal, los más recientes primero por páginas (?canal=jugadores&antes=ID&limite=50).
+// Cada página se devuelve en orden de lectura; para la anterior se pasa el ID del primer mensaje en ?antes
+func (h *ChatHandler) Historial(c *gin.Context) {
+	limite, err := strconv.Atoi(c.DefaultQuery("limite", strconv.Itoa(mensajesPorPagina)))
+	if err != nil || limite < 1 || limite > maxMensajesPorPagina {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Límite inválido"})
+		return
+	}
+	antes, err := strconv.ParseUint(c.DefaultQuery("antes", "0"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Mensaje inválido"})
+		return
+	}
+
+	mensajes, err := h.ChatService.Historial(c.Param("type"), c.Param("id"), middleware.JugadorActual(c), c.Query("canal"), uint(antes), limite)
+	if err != nil {
+		responderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"mensajes": mensajes})
+}
+
+// Silenciar — Deja de mostrar al jugador autenticado los mensajes de otro jugador ({"jugador_id": 2})
+func (h *ChatHandler) Silenciar(c *gin.Context) {
+	silenciadoID, ok := leerSilenciado(c)
+	if !ok {
+		return
+	}
+
+	if err := h.ChatService.Silenciar(c.Param("type"), c.Param("id"), middleware.JugadorActual(c).ID, silenciadoID); err != nil {
+		responderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Jugador silenciado"})
+}
+
+// QuitarSilencio — Vuelve a mostrar los mensajes del jugador silenciado
+func (h *ChatHandler) QuitarSilencio(c *gin.Context) {
+	silenciadoID, ok := leerSilenciado(c)
+	if !ok {
+		return
+	}
+
+	if err := h.ChatService.QuitarSilencio(c.Param("type"), c.Param("id"), middleware.JugadorActual(c).ID, silenciadoID); err != nil {
+		responderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Silencio retirado"})
+}
+
+// leerSilenciado lee el jugador a silenciar del cuerpo; si no es válido ya ha respondido
+func leerSilenciado(c *gin.Context) (uint, bool) {
+	var datos struct {
+		JugadorID uint `json:"jugador_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&datos); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return 0, false
+	}
+	return datos.JugadorID, true
+}
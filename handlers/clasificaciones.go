@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"juego/middleware"
+	"juego/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tamaño de página de las clasificaciones
+const (
+	puestosPorPagina    = 20
+	maxPuestosPorPagina = 100
+)
+
+// ClasificacionHandler publica las clasificaciones de cada tipo de juego
+type ClasificacionHandler struct {
+	EstadisticaService *services.EstadisticaService
+}
+
+func NewClasificacionHandler(estadisticaService *services.EstadisticaService) *ClasificacionHandler {
+	return &ClasificacionHandler{EstadisticaService: estadisticaService}
+}
+
+// Clasificacion — Mejores jugadores de un juego (?orden=puntuacion|victorias&periodo=siempre|mes|semana&limite=20&desplazamiento=0).
+// Si quien consulta se identifica, la respuesta incluye también su puesto
+func (h *ClasificacionHandler) Clasificacion(c *gin.Context) {
+	limite, err := strconv.Atoi(c.DefaultQuery("limite", strconv.Itoa(puestosPorPagina)))
+	if err != nil || limite < 1 || limite > maxPuestosPorPagina {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Límite inválido"})
+		return
+	}
+	desplazamiento, err := strconv.Atoi(c.DefaultQuery("desplazamiento", "0"))
+	if err != nil || desplazamiento < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Desplazamiento inválido"})
+		return
+	}
+	var jugadorID uint
+	if jugador := middleware.JugadorActual(c); jugador != nil {
+		jugadorID = jugador.ID
+	}
+
+	clasificacion, err := h.EstadisticaService.Clasificacion(c.Param("type"), c.Query("orden"), c.Query("periodo"), limite, desplazamiento, jugadorID)
+	if err != nil {
+		responderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clasificacion)
+}
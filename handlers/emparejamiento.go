@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"juego/middleware"
+	"juego/realtime"
+	"juego/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmparejamientoHandler expone las colas para buscar partida sin conocer a los rivales
+type EmparejamientoHandler struct {
+	EmparejamientoService *services.EmparejamientoService
+	Hub                   *realtime.Hub
+}
+
+func NewEmparejamientoHandler(emparejamientoService *services.EmparejamientoService, hub *realtime.Hub) *EmparejamientoHandler {
+	return &EmparejamientoHandler{EmparejamientoService: emparejamientoService, Hub: hub}
+}
+
+// Buscar — Mete al jugador autenticado en la cola del tipo de juego
+func (h *EmparejamientoHandler) Buscar(c *gin.Context) {
+	estado, err := h.EmparejamientoService.Buscar(c.Param("type"), middleware.JugadorActual(c))
+	if err != nil {
+		responderError(c, err)
+		return
+	}
+
+	status := http.StatusAccepted
+	if estado.Estado == services.EmparejamientoEmparejado {
+		status = http.StatusOK
+	}
+	c.JSON(status, gin.H{"emparejamiento": estado})
+}
+
+// Estado — Indica si el jugador sigue esperando o la partida que se le ha creado
+func (h *EmparejamientoHandler) Estado(c *gin.Context) {
+	estado := h.EmparejamientoService.Estado(middleware.JugadorActual(c).ID)
+	c.JSON(http.StatusOK, gin.H{"emparejamiento": estado})
+}
+
+// Cancelar — Saca al jugador de la cola
+func (h *EmparejamientoHandler) Cancelar(c *gin.Context) {
+	if err := h.EmparejamientoService.Cancelar(middleware.JugadorActual(c).ID); err != nil {
+		responderError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Búsqueda cancelada"})
+}
+
+// Eventos — Flujo Server-Sent Events con el estado de la búsqueda y el aviso
+// "emparejado" con el ID de la partida en cuanto se crea
+func (h *EmparejamientoHandler) Eventos(c *gin.Context) {
+	jugador := middleware.JugadorActual(c)
+
+	// Primero la suscripción: así no se pierde un emparejamiento entre ambos pasos
+	suscripcion := h.Hub.SuscribirJugador(jugador.ID)
+	defer suscripcion.Cancelar()
+	estado := h.EmparejamientoService.Estado(jugador.ID)
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	enviarSSE(c, realtime.Evento{Tipo: realtime.EventoEstado, PartidaID: estado.PartidaID, Numero: realtime.SinNumero, Datos: gin.H{"emparejamiento": estado}})
+
+	ping := time.NewTicker(intervaloPing)
+	defer ping.Stop()
+
+	for {
+		select {
+		case evento, abierta := <-suscripcion.Eventos:
+			if !abierta {
+				return
+			}
+			enviarSSE(c, evento)
+		case <-ping.C:
+			if _, err := c.Writer.WriteString(": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"juego/middleware"
+	"juego/qr"
+	"juego/services"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InvitacionHandler reparte los códigos para unirse a las partidas que esperan jugadores
+type InvitacionHandler struct {
+	JuegoService *services.JuegoService
+	EnlaceBase   string // Enlace al que se añade el código (p. ej. "juego://unirse/")
+}
+
+func NewInvitacionHandler(juegoService *services.JuegoService, enlaceBase string) *InvitacionHandler {
+	return &InvitacionHandler{JuegoService: juegoService, EnlaceBase: enlaceBase}
+}
+
+// Invitacion — Devuelve el código de la partida con su enlace para compartir.
+// Con ?formato=png|svg (y opcionalmente &tamano=256&nivel=L|M|Q|H) devuelve el
+// QR del enlace como imagen, que se puede usar directamente en un <img>
+func (h *InvitacionHandler) Invitacion(c *gin.Context) {
+	juego, libres, err := h.JuegoService.Invitacion(c.Param("type"), c.Param("id"))
+	if err != nil {
+		responderError(c, err)
+		return
+	}
+	codigo := juego.Comun().Codigo
+	enlace := h.EnlaceBase + codigo
+
+	if formato := c.Query("formato"); formato != "" {
+		opciones, err := qr.LeerOpciones(formato, c.Query("tamano"), c.Query("nivel"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		imagen, err := qr.Dibujar(enlace, opciones)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo dibujar el código QR"})
+			return
+		}
+		c.Data(http.StatusOK, opciones.TipoContenido(), imagen)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"codigo":          codigo,
+		"enlace":          enlace,
+		"qr":              c.Request.URL.Path + "?formato=" + qr.FormatoPNG,
+		"asientos_libres": libres,
+		"juego":           juego,
+	})
+}
+
+// Unirse — Sienta al jugador autenticado en un asiento libre de la partida del código
+func (h *InvitacionHandler) Unirse(c *gin.Context) {
+	jugador := middleware.JugadorActual(c)
+	if jugador == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Debes identificarte para unirte"})
+		return
+	}
+
+	juego, asiento, err := h.JuegoService.UnirseJuego(strings.ToUpper(c.Param("codigo")), jugador)
+	if err != nil {
+		responderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Te has unido a la partida", "asiento": asiento, "juego": juego})
+}
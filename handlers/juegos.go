@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"juego/engine"
+	"juego/ia"
+	"juego/mcts"
+	"juego/middleware"
+	"juego/models"
+	"juego/services"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JuegoHandler es la capa HTTP común a todos los juegos registrados en engine.
+// El tipo de juego se lee del parámetro :type de la ruta
+type JuegoHandler struct {
+	JuegoService *services.JuegoService
+}
+
+func NewJuegoHandler(juegoService *services.JuegoService) *JuegoHandler {
+	return &JuegoHandler{JuegoService: juegoService}
+}
+
+// ConTipo fija el tipo de juego para las rutas antiguas de cada juego (/crear-conecta-cuatro...)
+func ConTipo(tipo string, handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Params = append(c.Params, gin.Param{Key: "type", Value: tipo})
+		handler(c)
+	}
+}
+
+// Tipos — Lista los tipos de juego disponibles
+func (h *JuegoHandler) Tipos(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"tipos": engine.Types()})
+}
+
+// CrearJuego — Crea un nuevo juego con los jugadores del cuerpo de la solicitud. El jugador
+// autenticado juega en él: si no está en la lista ocupa el primer asiento libre.
+// Con ?bot=facil|medio|dificil|montecarlo el servidor juega en el siguiente asiento libre y
+// responde a cada movimiento; para que empiece el bot, se deja libre el primero ([{}, {"id": <tu id>}])
+func (h *JuegoHandler) CrearJuego(c *gin.Context) {
+	var jugadores []models.Jugador
+	if err := c.ShouldBindJSON(&jugadores); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	var juego engine.Game
+	var err error
+	if nivel := c.Query("bot"); nivel != "" {
+		juego, err = h.JuegoService.CrearJuegoContraBot(c.Param("type"), middleware.JugadorActual(c), jugadores, nivel)
+	} else {
+		juego, err = h.JuegoService.CrearJuego(c.Param("type"), middleware.JugadorActual(c), jugadores)
+	}
+	if err != nil {
+		responderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Juego creado", "juego": juego})
+}
+
+// ObtenerJuego — Obtiene el estado de un juego por su ID y cuántos espectadores lo miran
+func (h *JuegoHandler) ObtenerJuego(c *gin.Context) {
+	juego, err := h.JuegoService.ObtenerJuego(c.Param("type"), c.Param("id"))
+	if err != nil {
+		responderError(c, err)
+		return
+	}
+	espectadores := h.JuegoService.Hub.Espectadores(juego.Comun().ID)
+
+	c.JSON(http.StatusOK, gin.H{"juego": juego, "espectadores": espectadores.Total})
+}
+
+// Espectadores — Lista quiénes miran la partida en directo
+func (h *JuegoHandler) Espectadores(c *gin.Context) {
+	espectadores, err := h.JuegoService.Espectadores(c.Param("type"), c.Param("id"))
+	if err != nil {
+		responderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"espectadores": espectadores})
+}
+
+// HacerMovimiento — Valida y aplica el movimiento del jugador autenticado, si le toca.
+// Los espectadores no están sentados en la partida y reciben 403
+func (h *JuegoHandler) HacerMovimiento(c *gin.Context) {
+	jugador := middleware.JugadorActual(c)
+	if jugador == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Debes identificarte para mover"})
+		return
+	}
+
+	reglas, err := h.JuegoService.Reglas(c.Param("type"))
+	if err != nil {
+		responderError(c, err)
+		return
+	}
+
+	movimiento := reglas.NewMove()
+	if err := c.ShouldBindJSON(movimiento); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Movimiento inválido"})
+		return
+	}
+
+	juego, resultado, err := h.JuegoService.HacerMovimiento(c.Param("type"), c.Param("id"), jugador.ID, movimiento)
+	if err != nil {
+		responderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": mensajeResultado(resultado), "juego": juego})
+}
+
+// MovimientosLegales — Movimientos que puede hacer ahora el jugador con el turno: columnas en
+// Conecta Cuatro, colocaciones o pares origen-destino en Cuatro en Raya y celdas permitidas en Desde el Borde
+func (h *JuegoHandler) MovimientosLegales(c *gin.Context) {
+	turno, movimientos, err := h.JuegoService.MovimientosLegales(c.Param("type"), c.Param("id"))
+	if err != nil {
+		responderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"turno": turno, "movimientos": movimientos})
+}
+
+// Pista — Sugiere un movimiento al jugador autenticado, si le toca, con su valoración
+// (?nivel=facil|medio|dificil|montecarlo, por defecto dificil). Valoraciones desde ±500000 indican victoria o
+// derrota forzada; con montecarlo van de -1000 a 1000 según las partidas simuladas ganadas
+func (h *JuegoHandler) Pista(c *gin.Context) {
+	jugador := middleware.JugadorActual(c)
+	movimiento, valoracion, err := h.JuegoService.Pista(c.Param("type"), c.Param("id"), jugador.ID, c.DefaultQuery("nivel", ia.NivelDificil))
+	if err != nil {
+		responderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"movimiento": movimiento, "valoracion": valoracion})
+}
+
+// RepetirJuego — Devuelve el historial de movimientos y la posición en el paso pedido (?paso=N)
+func (h *JuegoHandler) RepetirJuego(c *gin.Context) {
+	paso := -1 // Sin paso se devuelve la posición final
+	if valor := c.Query("paso"); valor != "" {
+		var err error
+		if paso, err = strconv.Atoi(valor); err != nil || paso < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Paso inválido"})
+			return
+		}
+	}
+
+	repeticion, err := h.JuegoService.RepetirJuego(c.Param("type"), c.Param("id"), paso)
+	if err != nil {
+		responderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, repeticion)
+}
+
+// ReiniciarJuego — Deja la partida como recién creada (solo juegos que lo permiten).
+// Solo pueden hacerlo los jugadores de la partida
+func (h *JuegoHandler) ReiniciarJuego(c *gin.Context) {
+	juego, err := h.JuegoService.ReiniciarJuego(c.Param("type"), c.Param("id"), middleware.JugadorActual(c).ID)
+	if err != nil {
+		responderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Juego reiniciado", "juego": juego})
+}
+
+// TerminarJuego — Termina un juego; la partida sigue guardada para consultarla.
+// Solo pueden hacerlo los jugadores de la partida
+func (h *JuegoHandler) TerminarJuego(c *gin.Context) {
+	juego, err := h.JuegoService.TerminarJuego(c.Param("type"), c.Param("id"), middleware.JugadorActual(c).ID)
+	if err != nil {
+		responderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Juego terminado", "juego": juego})
+}
+
+// mensajeResultado describe cómo ha quedado la partida tras un movimiento
+func mensajeResultado(resultado engine.Outcome) string {
+	switch {
+	case !resultado.Finished:
+		return "Movimiento realizado"
+	case resultado.Winner == engine.NoWinner:
+		return "El juego terminó en empate"
+	default:
+		return fmt.Sprintf("¡Jugador %d ha ganado!", resultado.Winner+1)
+	}
+}
+
+// responderError traduce los errores del servicio a su código HTTP. Los que no
+// están en la lista son fallos del servidor: se registran y el cliente solo
+// recibe un mensaje genérico
+func responderError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrDemasiados), errors.Is(err, services.ErrNoReiniciable),
+		errors.Is(err, services.ErrAjustesSala), errors.Is(err, services.ErrSinTurnos),
+		errors.Is(err, services.ErrVariante), errors.Is(err, services.ErrTipoSinEmparejo),
+		errors.Is(err, services.ErrCanalDesconocido), errors.Is(err, services.ErrMensajeVacio),
+		errors.Is(err, services.ErrMensajeLargo), errors.Is(err, services.ErrSilencioNoValido),
+		errors.Is(err, services.ErrFechasNoValidas), errors.Is(err, services.ErrPasoFueraDeRango),
+		errors.Is(err, services.ErrOrdenClasificacion), errors.Is(err, services.ErrPeriodoClasificacion),
+		errors.Is(err, engine.ErrJuegoTerminado), errors.Is(err, engine.ErrMovimientoInvalido),
+		errors.Is(err, engine.ErrDestinoFueraTablero), errors.Is(err, engine.ErrDestinoOcupado),
+		errors.Is(err, engine.ErrColumnaLlena), errors.Is(err, engine.ErrOrigenFueraTablero),
+		errors.Is(err, engine.ErrOrigenSinFicha), errors.Is(err, engine.ErrBordeExterior),
+		errors.Is(err, engine.ErrJugadoresNoValidos), errors.Is(err, ia.ErrNivel):
+		status = http.StatusBadRequest
+	case errors.Is(err, services.ErrJuegoNoEncontrado), errors.Is(err, services.ErrTipoDesconocido),
+		errors.Is(err, services.ErrCodigoNoValido), errors.Is(err, services.ErrNoEnCola),
+		errors.Is(err, services.ErrJugadorNoEncontrado), errors.Is(err, ia.ErrSinIA),
+		errors.Is(err, services.ErrSinMovimientosLegales):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrNoParticipante), errors.Is(err, engine.ErrLanzamientoAjeno),
+		errors.Is(err, services.ErrNoAnfitrion), errors.Is(err, services.ErrCanalNoPermitido):
+		status = http.StatusForbidden
+	case errors.Is(err, services.ErrNoEsTuTurno), errors.Is(err, engine.ErrFueraDeTurno),
+		errors.Is(err, engine.ErrEsperandoJugadores), errors.Is(err, services.ErrPartidaCompleta), errors.Is(err, services.ErrYaParticipas),
+		errors.Is(err, services.ErrEnOtraCola), errors.Is(err, services.ErrSalaEmpezada),
+		errors.Is(err, services.ErrPartidaCambiada), errors.Is(err, services.ErrJugadorRepetido),
+		errors.Is(err, ia.ErrSinMovimientos), errors.Is(err, mcts.ErrSinMovimientos), errors.Is(err, mcts.ErrSinTurno):
+		status = http.StatusConflict
+	case errors.Is(err, services.ErrDemasiadosMensajes):
+		status = http.StatusTooManyRequests
+	}
+	if status == http.StatusInternalServerError {
+		log.Printf("handlers: %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		c.JSON(status, gin.H{"error": "Error interno del servidor"})
+		return
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
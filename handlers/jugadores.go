@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"juego/services"
+	"juego/store"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JugadorHandler publica lo que se sabe de cada jugador: sus estadísticas y
+// puntuaciones por tipo de juego
+type JugadorHandler struct {
+	EstadisticaService *services.EstadisticaService
+}
+
+func NewJugadorHandler(estadisticaService *services.EstadisticaService) *JugadorHandler {
+	return &JugadorHandler{EstadisticaService: estadisticaService}
+}
+
+// Tamaño de página del historial de puntuación y del de partidas
+const (
+	cambiosPorPagina     = 50
+	maxCambiosPorPagina  = 500
+	partidasPorPagina    = 20
+	maxPartidasPorPagina = 100
+)
+
+// Jugador — Datos públicos de un jugador con su puntuación en cada tipo de juego
+func (h *JugadorHandler) Jugador(c *gin.Context) {
+	jugadorID, ok := leerJugadorID(c)
+	if !ok {
+		return
+	}
+
+	jugador, puntuaciones, err := h.EstadisticaService.Perfil(jugadorID)
+	if err != nil {
+		responderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"jugador": jugador, "puntuaciones": puntuaciones})
+}
+
+// HistorialPuntuacion — Cómo ha cambiado la puntuación del jugador en un tipo de juego,
+// partida a partida y las más recientes primero (?limite=50)
+func (h *JugadorHandler) HistorialPuntuacion(c *gin.Context) {
+	jugadorID, ok := leerJugadorID(c)
+	if !ok {
+		return
+	}
+	limite, err := strconv.Atoi(c.DefaultQuery("limite", strconv.Itoa(cambiosPorPagina)))
+	if err != nil || limite < 1 || limite > maxCambiosPorPagina {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Límite inválido"})
+		return
+	}
+
+	historial, err := h.EstadisticaService.HistorialPuntuacion(jugadorID, c.Param("type"), limite)
+	if err != nil {
+		responderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"historial": historial})
+}
+
+// Estadisticas — Victorias, derrotas, empates, rachas y duración media de un jugador
+// en cada tipo de juego (?tipo=conecta_cuatro para uno solo)
+func (h *JugadorHandler) Estadisticas(c *gin.Context) {
+	jugadorID, ok := leerJugadorID(c)
+	if !ok {
+		return
+	}
+
+	jugador, estadisticas, err := h.EstadisticaService.Estadisticas(jugadorID, c.Query("tipo"))
+	if err != nil {
+		responderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"jugador": jugador, "estadisticas": estadisticas})
+}
+
+// Partidas — Partidas terminadas del jugador con sus rivales, resultado, duración y
+// enlace a la repetición (?tipo=conecta_cuatro&desde=2024-01-01&hasta=2024-01-31&limite=20&desplazamiento=0).
+// Las fechas pueden ser días (hasta incluye el día entero) o fechas RFC 3339
+func (h *JugadorHandler) Partidas(c *gin.Context) {
+	jugadorID, ok := leerJugadorID(c)
+	if !ok {
+		return
+	}
+	filtro := store.FiltroPartidas{TipoJuego: c.Query("tipo")}
+	var err error
+	if filtro.Limite, err = strconv.Atoi(c.DefaultQuery("limite", strconv.Itoa(partidasPorPagina))); err != nil || filtro.Limite < 1 || filtro.Limite > maxPartidasPorPagina {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Límite inválido"})
+		return
+	}
+	if filtro.Desplazamiento, err = strconv.Atoi(c.DefaultQuery("desplazamiento", "0")); err != nil || filtro.Desplazamiento < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Desplazamiento inválido"})
+		return
+	}
+	if filtro.Desde, err = leerFecha(c.Query("desde"), false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Fecha de inicio inválida"})
+		return
+	}
+	if filtro.Hasta, err = leerFecha(c.Query("hasta"), true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Fecha de fin inválida"})
+		return
+	}
+
+	historial, err := h.EstadisticaService.PartidasJugador(jugadorID, filtro)
+	if err != nil {
+		responderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, historial)
+}
+
+// leerFecha admite un día (2006-01-02, en UTC) o una fecha RFC 3339; vacía es nil.
+// Con finDeDia un día se toma hasta su final, para que el filtro lo incluya entero
+func leerFecha(valor string, finDeDia bool) (*time.Time, error) {
+	if valor == "" {
+		return nil, nil
+	}
+	if dia, err := time.Parse("2006-01-02", valor); err == nil {
+		if finDeDia {
+			dia = dia.AddDate(0, 0, 1)
+		}
+		return &dia, nil
+	}
+	fecha, err := time.Parse(time.RFC3339, valor)
+	if err != nil {
+		return nil, err
+	}
+	return &fecha, nil
+}
+
+// leerJugadorID lee el :id de la ruta; si no es válido ya ha respondido
+func leerJugadorID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Jugador inválido"})
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"juego/middleware"
+	"juego/models"
+	"juego/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tamaño de página del listado de salas
+const (
+	salasPorPagina    = 20
+	maxSalasPorPagina = 100
+)
+
+// SalaHandler es el lobby: crear salas con ajustes, listarlas y cerrarlas
+type SalaHandler struct {
+	JuegoService *services.JuegoService
+}
+
+func NewSalaHandler(juegoService *services.JuegoService) *SalaHandler {
+	return &SalaHandler{JuegoService: juegoService}
+}
+
+// CrearSala — Crea una sala con el jugador autenticado como anfitrión
+func (h *SalaHandler) CrearSala(c *gin.Context) {
+	var ajustes struct {
+		Privada       bool   `json:"privada"`
+		Variante      string `json:"variante"`
+		TiempoInicial int    `json:"tiempo_inicial"`
+		Incremento    int    `json:"incremento"`
+	}
+	// Sin cuerpo se crea una sala pública con los ajustes por defecto
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&ajustes); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+			return
+		}
+	}
+
+	sala := models.Sala{
+		Privada:       ajustes.Privada,
+		Variante:      ajustes.Variante,
+		TiempoInicial: ajustes.TiempoInicial,
+		Incremento:    ajustes.Incremento,
+	}
+	juego, err := h.JuegoService.CrearSala(c.Param("type"), middleware.JugadorActual(c), sala)
+	if err != nil {
+		responderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Sala creada", "juego": juego})
+}
+
+// ListarSalas — Salas públicas con asientos libres (?tipo=conecta_cuatro&limite=20&desplazamiento=0)
+func (h *SalaHandler) ListarSalas(c *gin.Context) {
+	limite, err := strconv.Atoi(c.DefaultQuery("limite", strconv.Itoa(salasPorPagina)))
+	if err != nil || limite < 1 || limite > maxSalasPorPagina {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Límite inválido"})
+		return
+	}
+	desplazamiento, err := strconv.Atoi(c.DefaultQuery("desplazamiento", "0"))
+	if err != nil || desplazamiento < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Desplazamiento inválido"})
+		return
+	}
+
+	salas, err := h.JuegoService.ListarSalas(c.Query("tipo"), limite, desplazamiento)
+	if err != nil {
+		responderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"salas": salas})
+}
+
+// CerrarSala — El anfitrión cierra su sala antes de que empiece la partida
+func (h *SalaHandler) CerrarSala(c *gin.Context) {
+	juego, err := h.JuegoService.CerrarSala(c.Param("type"), c.Param("id"), middleware.JugadorActual(c).ID)
+	if err != nil {
+		responderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sala cerrada", "juego": juego})
+}
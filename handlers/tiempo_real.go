@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"juego/middleware"
+	"juego/models"
+	"juego/realtime"
+	"juego/services"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// esperaEscritura es el tiempo máximo para enviar un mensaje al cliente
+	esperaEscritura = 10 * time.Second
+	// esperaPong es cuánto se espera la respuesta a un ping antes de dar la conexión por perdida
+	esperaPong = 60 * time.Second
+	// intervaloPing debe ser menor que esperaPong
+	intervaloPing = esperaPong * 9 / 10
+)
+
+// TiempoRealHandler envía a jugadores y espectadores los cambios de una partida
+// en cuanto se producen, sin que tengan que consultar el estado
+type TiempoRealHandler struct {
+	JuegoService *services.JuegoService
+	upgrader     websocket.Upgrader
+}
+
+func NewTiempoRealHandler(juegoService *services.JuegoService, origenes []string) *TiempoRealHandler {
+	return &TiempoRealHandler{
+		JuegoService: juegoService,
+		upgrader:     websocket.Upgrader{CheckOrigin: origenPermitido(origenes)},
+	}
+}
+
+// WebSocket — Abre una conexión que recibe el estado actual y después cada cambio de la partida
+func (h *TiempoRealHandler) WebSocket(c *gin.Context) {
+	suscripcion, juego, numero, err := h.JuegoService.Seguir(c.Param("type"), c.Param("id"), middleware.JugadorActual(c))
+	if err != nil {
+		responderError(c, err)
+		return
+	}
+	defer suscripcion.Cancelar()
+
+	conexion, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return // El upgrader ya ha respondido al cliente
+	}
+	defer conexion.Close()
+
+	estado := realtime.Evento{Tipo: realtime.EventoEstado, PartidaID: c.Param("id"), Numero: numero, Datos: gin.H{"juego": juego}}
+	if err := escribir(conexion, estado); err != nil {
+		return
+	}
+
+	cerrada := leerHastaCerrar(conexion)
+	ping := time.NewTicker(intervaloPing)
+	defer ping.Stop()
+
+	for {
+		select {
+		case evento, abierta := <-suscripcion.Eventos:
+			if !abierta {
+				return
+			}
+			if err := escribir(conexion, evento); err != nil {
+				return
+			}
+		case <-ping.C:
+			conexion.SetWriteDeadline(time.Now().Add(esperaEscritura))
+			if err := conexion.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-cerrada:
+			return
+		}
+	}
+}
+
+// Eventos — Flujo Server-Sent Events con los cambios de la partida, para clientes y
+// proxies sin WebSocket. El ID de cada evento es el número de movimiento: al
+// reconectar con Last-Event-ID se reciben primero los movimientos perdidos
+func (h *TiempoRealHandler) Eventos(c *gin.Context) {
+	tipo, id := c.Param("type"), c.Param("id")
+	suscripcion, juego, numero, err := h.JuegoService.Seguir(tipo, id, middleware.JugadorActual(c))
+	if err != nil {
+		responderError(c, err)
+		return
+	}
+	defer suscripcion.Cancelar()
+
+	// Movimientos que se perdió el cliente desde el último evento que recibió hasta
+	// el estado actual. Los posteriores se leen también del historial, porque la
+	// suscripción ya está abierta, pero se envían desde ella
+	var perdidos []models.Movimiento
+	if ultimo, err := strconv.Atoi(c.GetHeader("Last-Event-ID")); err == nil && ultimo < numero {
+		if perdidos, err = h.JuegoService.MovimientosDesde(tipo, id, ultimo); err != nil {
+			responderError(c, err)
+			return
+		}
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Que nginx no acumule el flujo
+
+	for _, movimiento := range perdidos {
+		if movimiento.Numero > numero {
+			// Ya llegará por la suscripción, después del estado en el que todavía no está
+			break
+		}
+		enviarSSE(c, realtime.Evento{Tipo: realtime.EventoMovimiento, PartidaID: id, Numero: movimiento.Numero, Datos: gin.H{"movimiento": movimiento}})
+	}
+	enviarSSE(c, realtime.Evento{Tipo: realtime.EventoEstado, PartidaID: id, Numero: numero, Datos: gin.H{"juego": juego}})
+
+	ping := time.NewTicker(intervaloPing)
+	defer ping.Stop()
+
+	for {
+		select {
+		case evento, abierta := <-suscripcion.Eventos:
+			if !abierta {
+				return
+			}
+			enviarSSE(c, evento)
+		case <-ping.C:
+			// Comentario SSE: mantiene viva la conexión a través de proxies
+			if _, err := c.Writer.WriteString(": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// enviarSSE escribe un evento con su número de movimiento como ID. Los eventos
+// sin número van sin ID para no mover el Last-Event-ID del cliente
+func enviarSSE(c *gin.Context, evento realtime.Evento) {
+	mensaje := sse.Event{Event: evento.Tipo, Data: evento}
+	if evento.Numero != realtime.SinNumero {
+		mensaje.Id = strconv.Itoa(evento.Numero)
+	}
+	c.Render(-1, mensaje)
+	c.Writer.Flush()
+}
+
+// escribir envía un evento al cliente en JSON
+func escribir(conexion *websocket.Conn, evento realtime.Evento) error {
+	conexion.SetWriteDeadline(time.Now().Add(esperaEscritura))
+	return conexion.WriteJSON(evento)
+}
+
+// leerHastaCerrar atiende los pongs y avisa cuando el cliente cierra la conexión.
+// Los mensajes del cliente se descartan: los movimientos siguen yendo por HTTP
+func leerHastaCerrar(conexion *websocket.Conn) <-chan struct{} {
+	cerrada := make(chan struct{})
+	conexion.SetReadDeadline(time.Now().Add(esperaPong))
+	conexion.SetPongHandler(func(string) error {
+		return conexion.SetReadDeadline(time.Now().Add(esperaPong))
+	})
+
+	go func() {
+		defer close(cerrada)
+		for {
+			if _, _, err := conexion.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	return cerrada
+}
+
+// origenPermitido acepta el mismo host, los orígenes configurados para CORS y
+// los clientes que no mandan Origin (aplicaciones nativas)
+func origenPermitido(origenes []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origen := r.Header.Get("Origin")
+		if origen == "" {
+			return true
+		}
+		for _, permitido := range origenes {
+			if permitido == "*" || permitido == origen {
+				return true
+			}
+		}
+		u, err := url.Parse(origen)
+		return err == nil && u.Host == r.Host
+	}
+}
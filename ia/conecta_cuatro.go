@@ -0,0 +1,88 @@
+package ia
+
+import (
+	"juego/engine"
+	"juego/models"
+	"sort"
+	"time"
+)
+
+func init() {
+	Registrar(engine.TipoConectaCuatro, Estrategia{
+		Evaluar: evaluarConecta,
+		Ordenar: ordenarConecta,
+		Niveles: map[string]Nivel{
+			NivelFacil:   {Profundidad: 2, Margen: 6},
+			NivelMedio:   {Profundidad: 5, Tiempo: time.Second},
+			NivelDificil: {Profundidad: 10, Tiempo: 2 * time.Second},
+		},
+	})
+}
+
+// evaluarConecta suma el valor de todas las líneas de cuatro celdas del tablero:
+// cuantas más fichas propias tenga una línea sin fichas del rival, mejor.
+// Además se premian las fichas de la columna central, que entran en más líneas
+func evaluarConecta(g engine.Game, asiento int) int {
+	tablero := g.(*models.ConectaCuatro).Tablero
+	propia, rival := engine.Ficha(asiento), engine.Ficha(1-asiento)
+
+	valor := 0
+	for fila := 0; fila < 6; fila++ {
+		if tablero[fila][3] == propia {
+			valor += 3
+		} else if tablero[fila][3] == rival {
+			valor -= 3
+		}
+	}
+
+	for fila := 0; fila < 6; fila++ {
+		for columna := 0; columna < 7; columna++ {
+			for _, direccion := range [][2]int{{0, 1}, {1, 0}, {1, 1}, {-1, 1}} {
+				finFila, finColumna := fila+3*direccion[0], columna+3*direccion[1]
+				if finFila < 0 || finFila >= 6 || finColumna >= 7 {
+					continue
+				}
+				propias, rivales := 0, 0
+				for paso := 0; paso < 4; paso++ {
+					switch tablero[fila+paso*direccion[0]][columna+paso*direccion[1]] {
+					case propia:
+						propias++
+					case rival:
+						rivales++
+					}
+				}
+				valor += valorLinea(propias, rivales)
+			}
+		}
+	}
+	return valor
+}
+
+// valorLinea valora una línea de cuatro celdas según las fichas de cada jugador
+func valorLinea(propias, rivales int) int {
+	switch {
+	case propias > 0 && rivales > 0:
+		return 0 // Ya no sirve a ninguno
+	case propias == 3:
+		return 5
+	case propias == 2:
+		return 2
+	case rivales == 3:
+		return -6 // Algo más que las propias: hay que taparlas
+	case rivales == 2:
+		return -2
+	}
+	return 0
+}
+
+// ordenarConecta prueba primero las columnas centrales, que suelen ser las mejores
+func ordenarConecta(_ engine.Game, movimientos []engine.Move) {
+	distancia := func(i int) int {
+		columna := movimientos[i].(*engine.MovimientoConecta).Columna
+		if columna < 3 {
+			return 3 - columna
+		}
+		return columna - 3
+	}
+	sort.SliceStable(movimientos, func(i, j int) bool { return distancia(i) < distancia(j) })
+}
@@ -0,0 +1,113 @@
+package ia
+
+import (
+	"juego/engine"
+	"juego/models"
+	"testing"
+)
+
+// conecta prepara una partida de Conecta Cuatro con el tablero dibujado (la
+// fila 5 abajo) y el turno del asiento indicado
+func conecta(t *testing.T, turno int, dibujo ...string) (engine.Searchable, *models.ConectaCuatro) {
+	t.Helper()
+	reglas, juego := enCurso(t, engine.TipoConectaCuatro)
+	conecta := juego.(*models.ConectaCuatro)
+	for fila := 0; fila < 6; fila++ {
+		for columna := 0; columna < 7; columna++ {
+			conecta.Tablero[fila][columna] = celda(t, dibujo, 6, 7, fila, columna)
+		}
+	}
+	conecta.Turno = turno
+	return reglas, conecta
+}
+
+func TestConectaCuatroGanaOTapa(t *testing.T) {
+	casos := []struct {
+		nombre  string
+		turno   int
+		dibujo  []string
+		columna int
+	}{
+		{"gana en horizontal", 0, []string{".......", ".......", ".......", ".......", "OO.....", "XXX...O"}, 3},
+		{"gana en vertical", 1, []string{".......", ".......", ".......", "O......", "O.X....", "OXX.X.."}, 0},
+		{"gana en diagonal", 0, []string{".......", ".......", ".......", "..XO...", ".XOO...", "XOOX..X"}, 3},
+		{"tapa la horizontal", 1, []string{".......", ".......", ".......", ".......", "O......", "OXXX..."}, 4},
+		{"tapa la vertical", 1, []string{".......", ".......", ".......", "..X....", "..X....", "..XOO.."}, 2},
+		{"ganar antes que tapar", 0, []string{".......", ".......", ".......", ".......", "OOO....", "XXX...."}, 3},
+	}
+	for _, caso := range casos {
+		for _, nivel := range niveles {
+			caso, nivel := caso, nivel
+			t.Run(caso.nombre+"/"+nivel, func(t *testing.T) {
+				t.Parallel()
+				reglas, juego := conecta(t, caso.turno, caso.dibujo...)
+				movimiento := elegirEnPlazo(t, juego, nivel)
+				if err := reglas.ValidateMove(juego, caso.turno, movimiento); err != nil {
+					t.Fatalf("movimiento %+v no válido: %v", movimiento, err)
+				}
+				if columna := movimiento.(*engine.MovimientoConecta).Columna; columna != caso.columna {
+					t.Fatalf("columna %d, se esperaba %d", columna, caso.columna)
+				}
+			})
+		}
+	}
+}
+
+func TestConectaCuatroSiempreLegal(t *testing.T) {
+	casos := []struct {
+		nombre string
+		turno  int
+		dibujo []string
+	}{
+		{"tablero vacío", 0, []string{".......", ".......", ".......", ".......", ".......", "......."}},
+		{"columnas llenas", 0, []string{"XO.OX.O", "OX.XO.X", "XO.OX.O", "OX.XO.X", "XO.OX.O", "OX.XO.X"}},
+		{"solo queda una columna", 1, []string{"XXOOXX.", "OOXXOOX", "XXOOXXO", "OOXXOOX", "XXOOXXO", "OOXXOOX"}},
+		{"perdida sin remedio", 1, []string{".......", ".......", ".......", ".......", ".......", ".XXX.OO"}},
+	}
+	for _, caso := range casos {
+		for _, nivel := range append(niveles, NivelMontecarlo) {
+			caso, nivel := caso, nivel
+			t.Run(caso.nombre+"/"+nivel, func(t *testing.T) {
+				t.Parallel()
+				reglas, juego := conecta(t, caso.turno, caso.dibujo...)
+				movimiento := elegirEnPlazo(t, juego, nivel)
+				if _, err := engine.Play(reglas, juego, caso.turno, movimiento); err != nil {
+					t.Fatalf("movimiento %+v no válido: %v", movimiento, err)
+				}
+			})
+		}
+	}
+}
+
+func TestEvaluarConecta(t *testing.T) {
+	casos := []struct {
+		nombre string
+		dibujo []string
+		signo  int // Signo de la valoración para X
+	}{
+		{"vacío", []string{".......", ".......", ".......", ".......", ".......", "......."}, 0},
+		{"X en el centro", []string{".......", ".......", ".......", ".......", ".......", "...X..."}, 1},
+		{"O en el centro", []string{".......", ".......", ".......", ".......", ".......", "...O..."}, -1},
+		{"tres de X abiertas contra fichas sueltas", []string{".......", ".......", ".......", ".......", "O.....O", ".XXX..."}, 1},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			_, juego := conecta(t, 0, caso.dibujo...)
+			valor := evaluarConecta(juego, 0)
+			if (valor > 0) != (caso.signo > 0) || (valor < 0) != (caso.signo < 0) {
+				t.Fatalf("valoración %d para X, se esperaba signo %d", valor, caso.signo)
+			}
+			// Para O es al revés, salvo que las amenazas del rival pesan algo más que las propias
+			if rival := evaluarConecta(juego, 1); caso.signo != 0 && (rival > 0) == (valor > 0) {
+				t.Fatalf("X ve %d y O ve %d: deberían tener signo contrario", valor, rival)
+			}
+		})
+	}
+
+	// Una línea de tres abierta vale más que una de dos
+	_, dos := conecta(t, 0, ".......", ".......", ".......", ".......", ".......", "XX.....")
+	_, tres := conecta(t, 0, ".......", ".......", ".......", ".......", ".......", "XXX....")
+	if evaluarConecta(tres, 0) <= evaluarConecta(dos, 0) {
+		t.Fatalf("tres en línea (%d) no vale más que dos (%d)", evaluarConecta(tres, 0), evaluarConecta(dos, 0))
+	}
+}
@@ -0,0 +1,87 @@
+package ia
+
+import (
+	"juego/engine"
+	"juego/models"
+	"sort"
+	"time"
+)
+
+func init() {
+	// La búsqueda ya encuentra las victorias forzadas y los bloqueos: el nivel
+	// fácil mira dos movimientos, lo justo para ganar o tapar en el acto
+	Registrar(engine.TipoCuatroEnRaya, Estrategia{
+		Evaluar: evaluarCuatroEnRaya,
+		Ordenar: ordenarCuatroEnRaya,
+		Niveles: map[string]Nivel{
+			NivelFacil:   {Profundidad: 2, Margen: 4},
+			NivelMedio:   {Profundidad: 4, Tiempo: time.Second},
+			NivelDificil: {Profundidad: 8, Tiempo: 2 * time.Second},
+		},
+	})
+}
+
+// lineas4x4 son las diez líneas de cuatro celdas del tablero de 4x4: filas,
+// columnas y las dos diagonales
+var lineas4x4 = func() [][4][2]int {
+	var lineas [][4][2]int
+	for i := 0; i < 4; i++ {
+		var fila, columna [4][2]int
+		for j := 0; j < 4; j++ {
+			fila[j] = [2]int{i, j}
+			columna[j] = [2]int{j, i}
+		}
+		lineas = append(lineas, fila, columna)
+	}
+	var diagonal, inversa [4][2]int
+	for i := 0; i < 4; i++ {
+		diagonal[i] = [2]int{i, i}
+		inversa[i] = [2]int{i, 3 - i}
+	}
+	return append(lineas, diagonal, inversa)
+}()
+
+// evaluarCuatroEnRaya suma el valor de las líneas del tablero de 4x4 y premia
+// las celdas centrales, que entran en tres líneas en vez de en dos
+func evaluarCuatroEnRaya(g engine.Game, asiento int) int {
+	tablero := g.(*models.CuatroEnRaya).Tablero
+	propia, rival := engine.Ficha(asiento), engine.Ficha(1-asiento)
+
+	valor := 0
+	for _, linea := range lineas4x4 {
+		propias, rivales := 0, 0
+		for _, celda := range linea {
+			switch tablero[celda[0]][celda[1]] {
+			case propia:
+				propias++
+			case rival:
+				rivales++
+			}
+		}
+		valor += valorLinea(propias, rivales)
+	}
+	for x := 1; x <= 2; x++ {
+		for y := 1; y <= 2; y++ {
+			if tablero[x][y] == propia {
+				valor++
+			} else if tablero[x][y] == rival {
+				valor--
+			}
+		}
+	}
+	return valor
+}
+
+// ordenarCuatroEnRaya prueba primero los destinos centrales
+func ordenarCuatroEnRaya(_ engine.Game, movimientos []engine.Move) {
+	central := func(i int) bool {
+		movimiento := movimientos[i].(*engine.MovimientoCuatroEnRaya)
+		return esCentro4x4(movimiento.DestinoX, movimiento.DestinoY)
+	}
+	sort.SliceStable(movimientos, func(i, j int) bool { return central(i) && !central(j) })
+}
+
+// esCentro4x4 indica si la celda es una de las cuatro centrales del tablero de 4x4
+func esCentro4x4(x, y int) bool {
+	return x >= 1 && x <= 2 && y >= 1 && y <= 2
+}
@@ -0,0 +1,103 @@
+package ia
+
+import (
+	"juego/engine"
+	"juego/models"
+	"testing"
+)
+
+// tablero4x4 prepara una partida de Cuatro en Raya (o de su variante desde el
+// borde) con el tablero dibujado; la primera coordenada es la fila
+func tablero4x4(t *testing.T, tipo string, turno int, dibujo ...string) (engine.Searchable, *models.CuatroEnRaya) {
+	t.Helper()
+	reglas, juego := enCurso(t, tipo)
+	cuatro := juego.(*models.CuatroEnRaya)
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 4; y++ {
+			cuatro.Tablero[x][y] = celda(t, dibujo, 4, 4, x, y)
+		}
+	}
+	cuatro.Turno = turno
+	return reglas, cuatro
+}
+
+func TestCuatroEnRayaGanaOTapa(t *testing.T) {
+	casos := []struct {
+		nombre  string
+		turno   int
+		dibujo  []string
+		origen  *[2]int // Solo en la fase de movimiento
+		destino [2]int
+	}{
+		{"coloca para ganar", 0, []string{"XXX.", "OO..", "O...", "...."}, nil, [2]int{0, 3}},
+		{"coloca para tapar", 1, []string{"XXX.", "O...", "....", "...O"}, nil, [2]int{0, 3}},
+		{"mueve para ganar", 0, []string{"XXX.", "OO..", "...O", "O..X"}, &[2]int{3, 3}, [2]int{0, 3}},
+		{"mueve para tapar", 1, []string{"XXX.", "O...", ".O..", "O.OX"}, nil, [2]int{0, 3}},
+	}
+	for _, caso := range casos {
+		for _, nivel := range niveles {
+			caso, nivel := caso, nivel
+			t.Run(caso.nombre+"/"+nivel, func(t *testing.T) {
+				t.Parallel()
+				reglas, juego := tablero4x4(t, engine.TipoCuatroEnRaya, caso.turno, caso.dibujo...)
+				movimiento := elegirEnPlazo(t, juego, nivel).(*engine.MovimientoCuatroEnRaya)
+				if movimiento.DestinoX != caso.destino[0] || movimiento.DestinoY != caso.destino[1] {
+					t.Fatalf("destino (%d,%d), se esperaba %v", movimiento.DestinoX, movimiento.DestinoY, caso.destino)
+				}
+				if caso.origen != nil && (movimiento.OrigenX != caso.origen[0] || movimiento.OrigenY != caso.origen[1]) {
+					t.Fatalf("origen (%d,%d), se esperaba %v", movimiento.OrigenX, movimiento.OrigenY, *caso.origen)
+				}
+				if _, err := engine.Play(reglas, juego, caso.turno, movimiento); err != nil {
+					t.Fatalf("movimiento %+v no válido: %v", movimiento, err)
+				}
+			})
+		}
+	}
+}
+
+func TestCuatroEnRayaSiempreLegal(t *testing.T) {
+	casos := []struct {
+		nombre string
+		turno  int
+		dibujo []string
+	}{
+		{"tablero vacío", 0, []string{"....", "....", "....", "...."}},
+		{"X ya mueve y O aún coloca", 1, []string{"X..O", ".X..", "..O.", "X..X"}},
+		{"los dos mueven", 0, []string{"XO..", "OX..", "..OX", "..XO"}},
+	}
+	for _, caso := range casos {
+		for _, nivel := range append(niveles, NivelMontecarlo) {
+			caso, nivel := caso, nivel
+			t.Run(caso.nombre+"/"+nivel, func(t *testing.T) {
+				t.Parallel()
+				reglas, juego := tablero4x4(t, engine.TipoCuatroEnRaya, caso.turno, caso.dibujo...)
+				fichas := contarFichas(juego.Tablero, engine.Ficha(caso.turno))
+				movimiento := elegirEnPlazo(t, juego, nivel)
+				if _, err := engine.Play(reglas, juego, caso.turno, movimiento); err != nil {
+					t.Fatalf("movimiento %+v no válido: %v", movimiento, err)
+				}
+				// Al colocar se suma una ficha; al mover se quedan las mismas
+				esperadas := fichas + 1
+				if fichas == 4 {
+					esperadas = 4
+				}
+				if despues := contarFichas(juego.Tablero, engine.Ficha(caso.turno)); despues != esperadas {
+					t.Fatalf("%d fichas tras el movimiento %+v, se esperaban %d", despues, movimiento, esperadas)
+				}
+			})
+		}
+	}
+}
+
+// contarFichas cuenta las fichas de un jugador en el tablero de 4x4
+func contarFichas(tablero [4][4]string, ficha string) int {
+	fichas := 0
+	for _, fila := range tablero {
+		for _, casilla := range fila {
+			if casilla == ficha {
+				fichas++
+			}
+		}
+	}
+	return fichas
+}
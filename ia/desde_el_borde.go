@@ -0,0 +1,20 @@
+package ia
+
+import (
+	"juego/engine"
+	"time"
+)
+
+func init() {
+	// Se juega sobre el mismo tablero que Cuatro en Raya, así que se valora igual.
+	// Las reglas ya solo ofrecen celdas del borde mientras quede alguna libre.
+	// La partida dura como mucho 16 movimientos: el nivel difícil puede verla entera
+	Registrar(engine.TipoDesdeElBorde, Estrategia{
+		Evaluar: evaluarCuatroEnRaya,
+		Niveles: map[string]Nivel{
+			NivelFacil:   {Profundidad: 2, Margen: 4},
+			NivelMedio:   {Profundidad: 5, Tiempo: time.Second},
+			NivelDificil: {Profundidad: 16, Tiempo: 2 * time.Second},
+		},
+	})
+}
@@ -0,0 +1,57 @@
+package ia
+
+import (
+	"juego/engine"
+	"testing"
+)
+
+// borde indica si la celda está en el anillo exterior del tablero de 4x4
+func borde(x, y int) bool {
+	return x == 0 || x == 3 || y == 0 || y == 3
+}
+
+func TestDesdeElBordeRespetaElAnillo(t *testing.T) {
+	casos := []struct {
+		nombre string
+		turno  int
+		dibujo []string
+		borde  bool // Si todos los movimientos tienen que ir al borde o al interior
+		libres int  // Movimientos legales que quedan
+	}{
+		{"tablero vacío", 0, []string{"....", "....", "....", "...."}, true, 12},
+		{"borde a medias", 0, []string{"X.O.", "O..X", "....", ".X.O"}, true, 6},
+		{"queda una celda del borde", 1, []string{"XOXO", "O..X", "X...", "OXOX"}, true, 1},
+		{"borde lleno", 0, []string{"XOXO", "O..X", "X..O", "OXOX"}, false, 4},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			reglas, juego := tablero4x4(t, engine.TipoDesdeElBorde, caso.turno, caso.dibujo...)
+			movimientos := reglas.LegalMoves(juego)
+			if len(movimientos) != caso.libres {
+				t.Fatalf("%d movimientos legales, se esperaban %d", len(movimientos), caso.libres)
+			}
+			for _, movimiento := range movimientos {
+				destino := movimiento.(*engine.MovimientoDesdeBorde)
+				if borde(destino.DestinoX, destino.DestinoY) != caso.borde {
+					t.Fatalf("movimiento generado (%d,%d) fuera de sitio", destino.DestinoX, destino.DestinoY)
+				}
+			}
+
+			for _, nivel := range append(niveles, NivelMontecarlo) {
+				nivel := nivel
+				t.Run(nivel, func(t *testing.T) {
+					t.Parallel()
+					reglas, juego := tablero4x4(t, engine.TipoDesdeElBorde, caso.turno, caso.dibujo...)
+					movimiento := elegirEnPlazo(t, juego, nivel)
+					destino := movimiento.(*engine.MovimientoDesdeBorde)
+					if borde(destino.DestinoX, destino.DestinoY) != caso.borde {
+						t.Fatalf("ha elegido (%d,%d) fuera de sitio", destino.DestinoX, destino.DestinoY)
+					}
+					if _, err := engine.Play(reglas, juego, caso.turno, movimiento); err != nil {
+						t.Fatalf("movimiento %+v no válido: %v", movimiento, err)
+					}
+				})
+			}
+		})
+	}
+}
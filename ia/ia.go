@@ -0,0 +1,347 @@
+// Package ia elige movimientos para los jugadores controlados por el servidor.
+// Busca con alfa-beta sobre las reglas de engine; cada juego solo aporta cómo
+// valorar una posición y qué fuerza tiene cada nivel de dificultad. El nivel
+// Montecarlo no necesita nada del juego: simula partidas con el paquete mcts.
+package ia
+
+import (
+	"errors"
+	"juego/engine"
+	"juego/mcts"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Niveles de dificultad
+const (
+	NivelFacil   = "facil"
+	NivelMedio   = "medio"
+	NivelDificil = "dificil"
+	// NivelMontecarlo busca con mcts en cualquier juego cuyas reglas lo permitan
+	NivelMontecarlo = "montecarlo"
+)
+
+// EscalaMontecarlo es la valoración de un movimiento que gana todas las
+// simulaciones; el que las pierde todas vale -EscalaMontecarlo
+const EscalaMontecarlo = 1000
+
+// Victoria es la valoración de una posición ganada; las victorias más cercanas valen más
+const Victoria = 1_000_000
+
+var (
+	ErrSinIA          = errors.New("Este juego no tiene jugador automático")
+	ErrNivel          = errors.New("Nivel de dificultad desconocido")
+	ErrSinMovimientos = errors.New("No hay movimientos posibles")
+)
+
+// Nivel es la fuerza de un nivel de dificultad
+type Nivel struct {
+	Profundidad int           // Movimientos que mira por delante como mucho
+	Tiempo      time.Duration // Tiempo máximo de búsqueda; 0 sin límite
+	Margen      int           // Elige al azar entre los movimientos que no pierden más que esto frente al mejor
+}
+
+// Estrategia es lo que cada juego aporta a la búsqueda
+type Estrategia struct {
+	// Evaluar valora la posición para el asiento indicado: positiva si va ganando
+	Evaluar func(g engine.Game, asiento int) int
+	// Ordenar coloca primero los movimientos que parecen mejores, para podar
+	// antes; es opcional
+	Ordenar func(g engine.Game, movimientos []engine.Move)
+	Niveles map[string]Nivel
+}
+
+var (
+	estrategias      = make(map[string]Estrategia)
+	estrategiasMutex sync.RWMutex
+)
+
+// Registrar añade la estrategia de un tipo de juego; la llaman los juegos desde init
+func Registrar(tipo string, estrategia Estrategia) {
+	estrategiasMutex.Lock()
+	defer estrategiasMutex.Unlock()
+	if _, existe := estrategias[tipo]; existe {
+		panic("ia: estrategia registrada dos veces: " + tipo)
+	}
+	estrategias[tipo] = estrategia
+}
+
+// Disponible indica si hay jugador automático para el tipo de juego en ese nivel
+func Disponible(tipo, nivel string) error {
+	if nivel == NivelMontecarlo {
+		_, err := simulable(tipo)
+		return err
+	}
+	estrategia, _, err := buscar(tipo)
+	if err != nil {
+		return err
+	}
+	if _, existe := estrategia.Niveles[nivel]; !existe {
+		return ErrNivel
+	}
+	return nil
+}
+
+// Elegir busca el movimiento del jugador que tiene el turno. montecarlo es el
+// presupuesto de la búsqueda en el nivel Montecarlo; los demás niveles no lo usan
+func Elegir(juego engine.Game, nivel string, montecarlo mcts.Config) (engine.Move, error) {
+	elegido, err := elegir(juego, nivel, montecarlo)
+	return elegido.movimiento, err
+}
+
+// Sugerir busca el movimiento del jugador que tiene el turno y devuelve también
+// cómo ve la posición tras él: positiva si le favorece, y a partir de Victoria/2
+// (o por debajo de -Victoria/2) si la partida ya está ganada (o perdida).
+// En el nivel Montecarlo va de -EscalaMontecarlo a EscalaMontecarlo según las
+// simulaciones ganadas
+func Sugerir(juego engine.Game, nivel string, montecarlo mcts.Config) (engine.Move, int, error) {
+	elegido, err := elegir(juego, nivel, montecarlo)
+	return elegido.movimiento, elegido.valor, err
+}
+
+func elegir(juego engine.Game, nivel string, montecarlo mcts.Config) (valorado, error) {
+	if nivel == NivelMontecarlo {
+		return elegirMontecarlo(juego, montecarlo)
+	}
+	estrategia, reglas, err := buscar(juego.Comun().TipoJuego)
+	if err != nil {
+		return valorado{}, err
+	}
+	fuerza, existe := estrategia.Niveles[nivel]
+	if !existe {
+		return valorado{}, ErrNivel
+	}
+
+	busqueda := &busqueda{
+		reglas:     reglas,
+		estrategia: estrategia,
+		azar:       rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	if fuerza.Tiempo > 0 {
+		busqueda.limite = time.Now().Add(fuerza.Tiempo)
+	}
+	return busqueda.raiz(juego, fuerza)
+}
+
+// buscar devuelve la estrategia y las reglas de un juego de dos jugadores, que
+// deben saber listar y copiar sus movimientos
+func buscar(tipo string) (Estrategia, engine.Searchable, error) {
+	estrategiasMutex.RLock()
+	estrategia, existe := estrategias[tipo]
+	estrategiasMutex.RUnlock()
+	reglas, err := simulable(tipo)
+	if !existe || err != nil || reglas.Seats() != 2 {
+		return Estrategia{}, nil, ErrSinIA
+	}
+	return estrategia, reglas, nil
+}
+
+// simulable devuelve las reglas de un juego si se pueden simular partidas con ellas
+func simulable(tipo string) (engine.Searchable, error) {
+	reglas, registrado := engine.Lookup(tipo)
+	if !registrado {
+		return nil, ErrSinIA
+	}
+	buscables, ok := reglas.(engine.Searchable)
+	if !ok {
+		return nil, ErrSinIA
+	}
+	return buscables, nil
+}
+
+// elegirMontecarlo busca con mcts y traduce la tasa de victorias a una valoración
+func elegirMontecarlo(juego engine.Game, config mcts.Config) (valorado, error) {
+	reglas, err := simulable(juego.Comun().TipoJuego)
+	if err != nil {
+		return valorado{}, err
+	}
+
+	resultado, err := mcts.Buscar(reglas, juego, config)
+	if err != nil {
+		return valorado{}, err
+	}
+	valor := 0 // Un único movimiento posible no se simula
+	if resultado.Visitas > 0 {
+		valor = int(math.Round((2*resultado.Tasa - 1) * EscalaMontecarlo))
+	}
+	return valorado{movimiento: resultado.Movimiento, valor: valor}, nil
+}
+
+// errTiempo corta la búsqueda cuando se acaba el tiempo
+var errTiempo = errors.New("tiempo agotado")
+
+// busqueda es una búsqueda alfa-beta (en forma negamax) con profundización iterativa
+type busqueda struct {
+	reglas     engine.Searchable
+	estrategia Estrategia
+	azar       *rand.Rand
+	limite     time.Time // Cero sin límite de tiempo
+	nodos      int
+}
+
+// valorado es un movimiento de la raíz con su valoración
+type valorado struct {
+	movimiento engine.Move
+	valor      int
+}
+
+// raiz profundiza de uno en uno hasta la profundidad del nivel o hasta que se
+// acaba el tiempo, y elige con la última profundidad completa
+func (b *busqueda) raiz(juego engine.Game, nivel Nivel) (valorado, error) {
+	candidatos := make([]valorado, 0)
+	for _, movimiento := range b.movimientos(juego) {
+		candidatos = append(candidatos, valorado{movimiento: movimiento})
+	}
+	asiento := b.reglas.CurrentPlayer(juego)
+	if len(candidatos) == 0 {
+		return valorado{}, ErrSinMovimientos
+	}
+	if len(candidatos) == 1 {
+		// No hay nada que elegir: basta con valorar la posición que deja
+		valor, err := b.hijo(juego, asiento, candidatos[0].movimiento, 0, -math.MaxInt32, math.MaxInt32)
+		return valorado{movimiento: candidatos[0].movimiento, valor: valor}, err
+	}
+	// Se barajan para que los movimientos igual de buenos no se jueguen siempre igual
+	b.azar.Shuffle(len(candidatos), func(i, j int) { candidatos[i], candidatos[j] = candidatos[j], candidatos[i] })
+	if b.estrategia.Ordenar != nil {
+		movimientos := make([]engine.Move, len(candidatos))
+		for i := range candidatos {
+			movimientos[i] = candidatos[i].movimiento
+		}
+		b.estrategia.Ordenar(juego, movimientos)
+		for i := range candidatos {
+			candidatos[i].movimiento = movimientos[i]
+		}
+	}
+
+	exacto := nivel.Margen > 0
+	var elegidos []valorado
+	for profundidad := 1; profundidad <= nivel.Profundidad; profundidad++ {
+		valorados, err := b.valorarRaiz(juego, asiento, candidatos, profundidad, exacto)
+		if len(valorados) > 0 {
+			elegidos = valorados
+		}
+		if err != nil {
+			break // Se acabó el tiempo: vale lo valorado hasta ahora
+		}
+		// El mejor de esta profundidad se prueba primero en la siguiente
+		candidatos = valorados
+		if mejor := valorados[0].valor; mejor >= Victoria/2 || mejor <= -Victoria/2 {
+			break // Ya se ve cómo acaba la partida
+		}
+	}
+	if elegidos == nil {
+		return candidatos[0], nil
+	}
+	if !exacto {
+		return elegidos[0], nil
+	}
+
+	validos := 1
+	for validos < len(elegidos) && elegidos[validos].valor >= elegidos[0].valor-nivel.Margen {
+		validos++
+	}
+	return elegidos[b.azar.Intn(validos)], nil
+}
+
+// valorarRaiz valora los movimientos de la raíz y los devuelve del mejor al peor.
+// Con exacto cada movimiento se busca con la ventana completa para conocer su
+// valor real y poder elegir al azar entre los parecidos; si no, solo el mejor es exacto.
+// Si se acaba el tiempo devuelve lo valorado hasta entonces, siempre que incluya el primero
+func (b *busqueda) valorarRaiz(juego engine.Game, asiento int, candidatos []valorado, profundidad int, exacto bool) ([]valorado, error) {
+	valorados := make([]valorado, 0, len(candidatos))
+	alfa := -math.MaxInt32
+	for _, candidato := range candidatos {
+		ventana := alfa
+		if exacto {
+			ventana = -math.MaxInt32
+		}
+		valor, err := b.hijo(juego, asiento, candidato.movimiento, profundidad-1, ventana, math.MaxInt32)
+		if err != nil {
+			if len(valorados) == 0 {
+				return nil, err
+			}
+			ordenarValorados(valorados)
+			return valorados, err
+		}
+		valorados = append(valorados, valorado{movimiento: candidato.movimiento, valor: valor})
+		if valor > alfa {
+			alfa = valor
+		}
+	}
+	ordenarValorados(valorados)
+	return valorados, nil
+}
+
+// hijo aplica un movimiento sobre una copia y devuelve su valor para el asiento que mueve
+func (b *busqueda) hijo(juego engine.Game, asiento int, movimiento engine.Move, profundidad, alfa, beta int) (int, error) {
+	copia := b.reglas.Clone(juego)
+	b.reglas.ApplyMove(copia, asiento, movimiento)
+	if b.reglas.CurrentPlayer(copia) == asiento {
+		return b.negamax(copia, profundidad, alfa, beta)
+	}
+	valor, err := b.negamax(copia, profundidad, -beta, -alfa)
+	return -valor, err
+}
+
+// negamax valora la posición para el jugador que tiene el turno
+func (b *busqueda) negamax(juego engine.Game, profundidad, alfa, beta int) (int, error) {
+	b.nodos++
+	if b.nodos%1024 == 0 && !b.limite.IsZero() && time.Now().After(b.limite) {
+		return 0, errTiempo
+	}
+
+	asiento := b.reglas.CurrentPlayer(juego)
+	if resultado := b.reglas.Outcome(juego); resultado.Finished {
+		switch resultado.Winner {
+		case engine.NoWinner:
+			return 0, nil
+		case asiento:
+			return Victoria + profundidad, nil
+		default:
+			return -Victoria - profundidad, nil
+		}
+	}
+	if profundidad == 0 {
+		return b.estrategia.Evaluar(juego, asiento), nil
+	}
+
+	movimientos := b.movimientos(juego)
+	if len(movimientos) == 0 {
+		return b.estrategia.Evaluar(juego, asiento), nil
+	}
+	if b.estrategia.Ordenar != nil {
+		b.estrategia.Ordenar(juego, movimientos)
+	}
+
+	mejor := -math.MaxInt32
+	for _, movimiento := range movimientos {
+		valor, err := b.hijo(juego, asiento, movimiento, profundidad-1, alfa, beta)
+		if err != nil {
+			return 0, err
+		}
+		if valor > mejor {
+			mejor = valor
+		}
+		if valor > alfa {
+			alfa = valor
+		}
+		if alfa >= beta {
+			break
+		}
+	}
+	return mejor, nil
+}
+
+// movimientos lista los movimientos legales de la posición
+func (b *busqueda) movimientos(juego engine.Game) []engine.Move {
+	return b.reglas.LegalMoves(juego)
+}
+
+// ordenarValorados deja primero los mejores sin deshacer el orden de los empatados
+func ordenarValorados(valorados []valorado) {
+	sort.SliceStable(valorados, func(i, j int) bool { return valorados[i].valor > valorados[j].valor })
+}
@@ -0,0 +1,110 @@
+package ia
+
+import (
+	"juego/engine"
+	"juego/mcts"
+	"juego/models"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// niveles son los niveles de alfa-beta, del más débil al más fuerte
+var niveles = []string{NivelFacil, NivelMedio, NivelDificil}
+
+// montecarlo es un presupuesto pequeño y con semilla para las pruebas
+var montecarlo = mcts.Config{Iteraciones: 2000, Semilla: 1}
+
+// celda lee un dibujo del tablero (una cadena por fila, '.' para las celdas libres)
+func celda(t *testing.T, dibujo []string, filas, columnas, fila, columna int) string {
+	t.Helper()
+	if len(dibujo) != filas || len(dibujo[fila]) != columnas {
+		t.Fatalf("el dibujo %q no es de %dx%d", dibujo, filas, columnas)
+	}
+	if dibujo[fila][columna] == '.' {
+		return ""
+	}
+	return string(dibujo[fila][columna])
+}
+
+// enCurso prepara una partida de dos jugadores con el tipo indicado ya empezada
+func enCurso(t *testing.T, tipo string) (engine.Searchable, engine.Game) {
+	t.Helper()
+	reglas, existe := engine.Lookup(tipo)
+	if !existe {
+		t.Fatalf("%s no está registrado", tipo)
+	}
+	juego := reglas.NewState("prueba", []models.Jugador{{ID: 1}, {ID: 2}})
+	juego.Comun().Estado = models.EstadoEnProgreso
+	return reglas.(engine.Searchable), juego
+}
+
+// elegirEnPlazo elige con el nivel indicado y comprueba que la búsqueda respeta
+// el tiempo del nivel (con margen para la última profundidad a medias)
+func elegirEnPlazo(t *testing.T, juego engine.Game, nivel string) engine.Move {
+	t.Helper()
+	inicio := time.Now()
+	movimiento, err := Elegir(juego, nivel, montecarlo)
+	if err != nil {
+		t.Fatalf("nivel %s: %v", nivel, err)
+	}
+	estrategia, _, err := buscar(juego.Comun().TipoJuego)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plazo := estrategia.Niveles[nivel].Tiempo; plazo > 0 && time.Since(inicio) > plazo+time.Second {
+		t.Fatalf("nivel %s: %v buscando con un plazo de %v", nivel, time.Since(inicio), plazo)
+	}
+	return movimiento
+}
+
+func TestNivelesDeCadaJuego(t *testing.T) {
+	// Cada nivel mira más lejos que el anterior; los que miran lejos tienen un tope de tiempo
+	for _, tipo := range []string{engine.TipoConectaCuatro, engine.TipoCuatroEnRaya, engine.TipoDesdeElBorde} {
+		t.Run(tipo, func(t *testing.T) {
+			estrategia, _, err := buscar(tipo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			anterior := 0
+			for _, nivel := range niveles {
+				fuerza, existe := estrategia.Niveles[nivel]
+				if !existe {
+					t.Fatalf("falta el nivel %s", nivel)
+				}
+				if fuerza.Profundidad <= anterior {
+					t.Fatalf("nivel %s con profundidad %d, no más que el anterior (%d)", nivel, fuerza.Profundidad, anterior)
+				}
+				if nivel != NivelFacil && fuerza.Tiempo <= 0 {
+					t.Fatalf("nivel %s sin límite de tiempo", nivel)
+				}
+				anterior = fuerza.Profundidad
+			}
+			if err := Disponible(tipo, "imposible"); err != ErrNivel {
+				t.Fatalf("error %v, se esperaba %v", err, ErrNivel)
+			}
+		})
+	}
+	if err := Disponible(engine.TipoPasaBolas, NivelFacil); err != ErrSinIA {
+		t.Fatalf("error %v, se esperaba %v", err, ErrSinIA)
+	}
+}
+
+func TestSinTiempoDevuelveUnMovimiento(t *testing.T) {
+	// Con el tiempo ya agotado la búsqueda se corta en la primera profundidad y aun
+	// así devuelve uno de los movimientos legales
+	reglas, juego := enCurso(t, engine.TipoConectaCuatro)
+	estrategia, _, err := buscar(engine.TipoConectaCuatro)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &busqueda{reglas: reglas, estrategia: estrategia, azar: rand.New(rand.NewSource(1)), limite: time.Now().Add(-time.Second)}
+	b.nodos = 1023 // El siguiente nodo comprueba el reloj
+	elegido, err := b.raiz(juego, Nivel{Profundidad: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := reglas.ValidateMove(juego, 0, elegido.movimiento); err != nil {
+		t.Fatalf("movimiento %+v no válido: %v", elegido.movimiento, err)
+	}
+}
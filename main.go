@@ -2,26 +2,39 @@ package main
 
 import (
 	"fmt"
-	"juego/db"
+	"juego/config"
 	"juego/routes"
+	"juego/store"
 	"log"
+	"os"
 )
 
 func main() {
-	// Conexión a la base de datos
-	db.Connect()
+	// Configuración: fichero opcional (JUEGO_CONFIG) y variables de entorno
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// Almacenamiento: memoria (pruebas), sqlite (desarrollo) o mysql (producción)
+	gameStore, err := store.Open(cfg.DB.Driver, cfg.DB.DSN)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 	// cierra la conexión
 	defer func() {
-		if err := db.Close(); err != nil {
+		if err := gameStore.Close(); err != nil {
 			log.Printf("Error closing database: %v", err)
 		}
 	}()
 
 	// Usar el enrutador de Gin
-	r := routes.SetupRouter()
+	r := routes.SetupRouter(cfg, gameStore)
 
-	fmt.Println("Servidor ejecutándose en el puerto 8080...")
-	err := r.Run(":8081") // Esto ya maneja ListenAndServe internamente
+	fmt.Printf("Servidor ejecutándose en %s...\n", cfg.Direccion)
+	err = r.Run(cfg.Direccion) // Esto ya maneja ListenAndServe internamente
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,206 @@
+// Package mcts elige movimientos con una búsqueda de árbol Monte Carlo (UCT).
+// No sabe nada de ningún juego en concreto: le basta con unas reglas de engine
+// que sepan listar sus movimientos legales y copiar la partida, y valora cada
+// movimiento jugando partidas al azar a partir de él
+package mcts
+
+import (
+	"errors"
+	"juego/engine"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Valores por defecto de la configuración
+const (
+	ExploracionPorDefecto = math.Sqrt2
+	MaxJugadasPorDefecto  = 60
+)
+
+var (
+	ErrSinPresupuesto = errors.New("La búsqueda necesita un límite de iteraciones o de tiempo")
+	ErrSinMovimientos = errors.New("No hay movimientos posibles")
+	ErrSinTurno       = errors.New("La búsqueda necesita saber a quién le toca")
+)
+
+// Config es el presupuesto y el comportamiento de una búsqueda. Hace falta al
+// menos un límite; con los dos, la búsqueda para con el primero que se alcance
+type Config struct {
+	Iteraciones int           // Partidas simuladas como mucho; 0 sin límite
+	Tiempo      time.Duration // Tiempo máximo de búsqueda; 0 sin límite
+	// Semilla fija el azar: con la misma semilla, la misma posición y solo
+	// límite de iteraciones, la búsqueda elige siempre igual. 0 usa la hora
+	Semilla int64
+	// Exploracion es la constante de UCT: cuanto mayor, más se prueban los
+	// movimientos poco visitados. 0 usa ExploracionPorDefecto
+	Exploracion float64
+	// MaxJugadas corta las simulaciones que no acaban (en Cuatro en Raya las
+	// fichas se mueven sin fin) y las cuenta como empate. 0 usa MaxJugadasPorDefecto
+	MaxJugadas int
+}
+
+// Resultado es el movimiento elegido y lo que la búsqueda sabe de él
+type Resultado struct {
+	Movimiento  engine.Move
+	Visitas     int     // Simulaciones que empezaron por este movimiento
+	Tasa        float64 // Parte de esas simulaciones que ganó quien mueve; los empates cuentan como media
+	Iteraciones int     // Simulaciones hechas en total
+}
+
+// nodo es una posición del árbol, a la que se llega con movimiento
+type nodo struct {
+	padre      *nodo
+	movimiento engine.Move
+	asiento    int // Quien ha hecho el movimiento; sus puntos son los de este asiento. La raíz no tiene
+	hijos      []*nodo
+	pendientes []engine.Move // Movimientos que aún no tienen nodo
+	visitas    int
+	puntos     float64 // 1 por simulación ganada por asiento y 0,5 por empate
+}
+
+// Buscar elige el movimiento del jugador que tiene el turno: el más visitado
+// tras simular partidas dentro del presupuesto
+func Buscar(reglas engine.Searchable, juego engine.Game, config Config) (Resultado, error) {
+	if config.Iteraciones <= 0 && config.Tiempo <= 0 {
+		return Resultado{}, ErrSinPresupuesto
+	}
+	if config.Exploracion <= 0 {
+		config.Exploracion = ExploracionPorDefecto
+	}
+	if config.MaxJugadas <= 0 {
+		config.MaxJugadas = MaxJugadasPorDefecto
+	}
+	semilla := config.Semilla
+	if semilla == 0 {
+		semilla = time.Now().UnixNano()
+	}
+	if reglas.Outcome(juego).Finished {
+		return Resultado{}, ErrSinMovimientos
+	}
+	if reglas.CurrentPlayer(juego) == engine.AnySeat {
+		return Resultado{}, ErrSinTurno
+	}
+
+	raiz := &nodo{pendientes: reglas.LegalMoves(juego)}
+	if len(raiz.pendientes) == 0 {
+		return Resultado{}, ErrSinMovimientos
+	}
+	if len(raiz.pendientes) == 1 {
+		return Resultado{Movimiento: raiz.pendientes[0]}, nil
+	}
+
+	b := &busqueda{reglas: reglas, config: config, azar: rand.New(rand.NewSource(semilla))}
+	var limite time.Time
+	if config.Tiempo > 0 {
+		limite = time.Now().Add(config.Tiempo)
+	}
+	iteraciones := 0
+	for config.Iteraciones <= 0 || iteraciones < config.Iteraciones {
+		// Mirar el reloj en cada iteración cuesta poco comparado con una simulación
+		if !limite.IsZero() && time.Now().After(limite) {
+			break
+		}
+		b.iterar(raiz, juego)
+		iteraciones++
+	}
+
+	if len(raiz.hijos) == 0 {
+		// Ni una simulación a tiempo: cualquier movimiento legal vale
+		return Resultado{Movimiento: raiz.pendientes[0], Iteraciones: iteraciones}, nil
+	}
+	mejor := raiz.hijos[0]
+	for _, hijo := range raiz.hijos[1:] {
+		if hijo.visitas > mejor.visitas {
+			mejor = hijo
+		}
+	}
+	return Resultado{
+		Movimiento:  mejor.movimiento,
+		Visitas:     mejor.visitas,
+		Tasa:        mejor.puntos / float64(mejor.visitas),
+		Iteraciones: iteraciones,
+	}, nil
+}
+
+type busqueda struct {
+	reglas engine.Searchable
+	config Config
+	azar   *rand.Rand
+}
+
+// iterar hace una vuelta completa: baja por el árbol eligiendo con UCT, añade
+// un nodo nuevo, simula desde él hasta el final y reparte el resultado hacia arriba
+func (b *busqueda) iterar(raiz *nodo, juego engine.Game) {
+	copia := b.reglas.Clone(juego)
+
+	actual := raiz
+	for len(actual.pendientes) == 0 && len(actual.hijos) > 0 {
+		actual = b.seleccionar(actual)
+		b.reglas.ApplyMove(copia, actual.asiento, actual.movimiento)
+	}
+
+	if len(actual.pendientes) > 0 {
+		i := b.azar.Intn(len(actual.pendientes))
+		movimiento := actual.pendientes[i]
+		actual.pendientes[i] = actual.pendientes[len(actual.pendientes)-1]
+		actual.pendientes = actual.pendientes[:len(actual.pendientes)-1]
+
+		asiento := b.reglas.CurrentPlayer(copia)
+		b.reglas.ApplyMove(copia, asiento, movimiento)
+		hijo := &nodo{padre: actual, movimiento: movimiento, asiento: asiento}
+		if !b.reglas.Outcome(copia).Finished {
+			hijo.pendientes = b.reglas.LegalMoves(copia)
+		}
+		actual.hijos = append(actual.hijos, hijo)
+		actual = hijo
+	}
+
+	ganador := b.simular(copia)
+	for ; actual != nil; actual = actual.padre {
+		actual.visitas++
+		if actual.padre == nil {
+			break // La raíz no viene de ningún movimiento: solo cuenta sus visitas
+		}
+		switch ganador {
+		case actual.asiento:
+			actual.puntos++
+		case engine.NoWinner:
+			actual.puntos += 0.5
+		}
+	}
+}
+
+// seleccionar devuelve el hijo con mayor valor UCT: su tasa de victorias más un
+// premio que crece para los hijos poco visitados
+func (b *busqueda) seleccionar(padre *nodo) *nodo {
+	logaritmo := math.Log(float64(padre.visitas))
+	var mejor *nodo
+	mejorValor := math.Inf(-1)
+	for _, hijo := range padre.hijos {
+		valor := hijo.puntos/float64(hijo.visitas) + b.config.Exploracion*math.Sqrt(logaritmo/float64(hijo.visitas))
+		if valor > mejorValor {
+			mejor, mejorValor = hijo, valor
+		}
+	}
+	return mejor
+}
+
+// simular juega al azar desde la posición hasta el final o hasta MaxJugadas y
+// devuelve el asiento ganador, o NoWinner si es empate o no ha acabado
+func (b *busqueda) simular(juego engine.Game) int {
+	for jugada := 0; jugada < b.config.MaxJugadas; jugada++ {
+		if resultado := b.reglas.Outcome(juego); resultado.Finished {
+			return resultado.Winner
+		}
+		movimientos := b.reglas.LegalMoves(juego)
+		if len(movimientos) == 0 {
+			return engine.NoWinner
+		}
+		b.reglas.ApplyMove(juego, b.reglas.CurrentPlayer(juego), movimientos[b.azar.Intn(len(movimientos))])
+	}
+	if resultado := b.reglas.Outcome(juego); resultado.Finished {
+		return resultado.Winner
+	}
+	return engine.NoWinner
+}
@@ -0,0 +1,118 @@
+package mcts
+
+import (
+	"errors"
+	"juego/engine"
+	"juego/models"
+	"testing"
+	"time"
+)
+
+// conecta devuelve las reglas de Conecta Cuatro y una partida con las fichas
+// indicadas ({fila, columna} de cada una, con la fila 5 abajo)
+func conecta(t *testing.T, turno int, equis, oes [][2]int) (engine.Searchable, *models.ConectaCuatro) {
+	t.Helper()
+	reglas, existe := engine.Lookup(engine.TipoConectaCuatro)
+	if !existe {
+		t.Fatal("Conecta Cuatro no está registrado")
+	}
+	juego := reglas.NewState("prueba", []models.Jugador{{ID: 1}, {ID: 2}}).(*models.ConectaCuatro)
+	for _, celda := range equis {
+		juego.Tablero[celda[0]][celda[1]] = engine.Ficha(0)
+	}
+	for _, celda := range oes {
+		juego.Tablero[celda[0]][celda[1]] = engine.Ficha(1)
+	}
+	juego.Turno = turno
+	return reglas.(engine.Searchable), juego
+}
+
+func TestMismaSemillaMismoMovimiento(t *testing.T) {
+	reglas, juego := conecta(t, 0, [][2]int{{5, 3}, {5, 4}}, [][2]int{{4, 3}, {5, 2}})
+	config := Config{Iteraciones: 3000, Semilla: 7}
+
+	primero, err := Buscar(reglas, juego, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		otro, err := Buscar(reglas, juego, config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *otro.Movimiento.(*engine.MovimientoConecta) != *primero.Movimiento.(*engine.MovimientoConecta) ||
+			otro.Visitas != primero.Visitas || otro.Tasa != primero.Tasa {
+			t.Fatalf("con la misma semilla salió %+v y luego %+v", primero, otro)
+		}
+	}
+}
+
+func TestMovimientosForzados(t *testing.T) {
+	casos := []struct {
+		nombre  string
+		equis   [][2]int
+		oes     [][2]int
+		columna int
+	}{
+		{
+			nombre:  "gana en uno",
+			equis:   [][2]int{{5, 0}, {5, 1}, {5, 2}},
+			oes:     [][2]int{{4, 0}, {4, 1}, {5, 6}},
+			columna: 3,
+		},
+		{
+			nombre:  "tapa la derrota en uno",
+			equis:   [][2]int{{5, 6}, {4, 6}, {5, 5}},
+			oes:     [][2]int{{5, 0}, {5, 1}, {5, 2}},
+			columna: 3,
+		},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			reglas, juego := conecta(t, 0, caso.equis, caso.oes)
+			resultado, err := Buscar(reglas, juego, Config{Iteraciones: 5000, Semilla: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if columna := resultado.Movimiento.(*engine.MovimientoConecta).Columna; columna != caso.columna {
+				t.Errorf("eligió la columna %d, se esperaba la %d", columna, caso.columna)
+			}
+		})
+	}
+}
+
+func TestPresupuestoIteraciones(t *testing.T) {
+	reglas, juego := conecta(t, 0, nil, nil)
+	resultado, err := Buscar(reglas, juego, Config{Iteraciones: 500, Semilla: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resultado.Iteraciones != 500 {
+		t.Errorf("hizo %d iteraciones, se esperaban 500", resultado.Iteraciones)
+	}
+}
+
+func TestPresupuestoTiempo(t *testing.T) {
+	reglas, juego := conecta(t, 0, nil, nil)
+	tiempo := 50 * time.Millisecond
+
+	inicio := time.Now()
+	resultado, err := Buscar(reglas, juego, Config{Tiempo: tiempo, Semilla: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Se comprueba el reloj antes de cada simulación, que dura mucho menos que el margen
+	if transcurrido := time.Since(inicio); transcurrido > tiempo+100*time.Millisecond {
+		t.Errorf("tardó %v con un presupuesto de %v", transcurrido, tiempo)
+	}
+	if resultado.Iteraciones == 0 || resultado.Movimiento == nil {
+		t.Errorf("no simuló nada: %+v", resultado)
+	}
+}
+
+func TestSinPresupuesto(t *testing.T) {
+	reglas, juego := conecta(t, 0, nil, nil)
+	if _, err := Buscar(reglas, juego, Config{}); !errors.Is(err, ErrSinPresupuesto) {
+		t.Errorf("sin límites devolvió %v, se esperaba ErrSinPresupuesto", err)
+	}
+}
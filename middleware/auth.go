@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"juego/models"
+	"juego/services"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// claveJugador es la clave del contexto de gin donde se guarda el jugador autenticado
+const claveJugador = "jugador"
+
+// RequiereJugador rechaza las peticiones sin credenciales válidas y guarda en el
+// contexto el jugador autenticado. Solo se acepta el token de acceso de /login
+// (Authorization: Bearer)
+func RequiereJugador(sesionService *services.SesionService) gin.HandlerFunc {
+	return requerir(sesionService, TokenBearer)
+}
+
+// RequiereJugadorEnFlujo es RequiereJugador para las rutas de WebSocket y
+// Server-Sent Events, que también admiten el token en la URL (ver tokenDeFlujo)
+func RequiereJugadorEnFlujo(sesionService *services.SesionService) gin.HandlerFunc {
+	return requerir(sesionService, tokenDeFlujo)
+}
+
+// IdentificarJugador guarda en el contexto el jugador si la petición trae
+// credenciales, pero deja pasar las anónimas
+func IdentificarJugador(sesionService *services.SesionService) gin.HandlerFunc {
+	return identificar(sesionService, TokenBearer)
+}
+
+// IdentificarEnFlujo es IdentificarJugador para las rutas de WebSocket y
+// Server-Sent Events, que también admiten el token en la URL (ver tokenDeFlujo)
+func IdentificarEnFlujo(sesionService *services.SesionService) gin.HandlerFunc {
+	return identificar(sesionService, tokenDeFlujo)
+}
+
+func requerir(sesionService *services.SesionService, leerToken func(*gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		jugador, presentado, err := autenticar(leerToken(c), sesionService)
+		if !presentado {
+			c.Header("WWW-Authenticate", `Bearer realm="juego"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Debes identificarte para hacer esto"})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set(claveJugador, jugador)
+		c.Next()
+	}
+}
+
+func identificar(sesionService *services.SesionService, leerToken func(*gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		jugador, presentado, err := autenticar(leerToken(c), sesionService)
+		if presentado && err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if presentado {
+			c.Set(claveJugador, jugador)
+		}
+		c.Next()
+	}
+}
+
+// tokenDeFlujo devuelve el token de la cabecera o, como los navegadores no
+// pueden añadir cabeceras a WebSocket ni a EventSource, el del parámetro
+// ?access_token=. Para que no quede en los registros, Registro lo oculta
+func tokenDeFlujo(c *gin.Context) string {
+	if token := TokenBearer(c); token != "" {
+		return token
+	}
+	return c.Query("access_token")
+}
+
+// autenticar comprueba el token de acceso. presentado es false cuando la
+// petición no trae ninguno
+func autenticar(token string, sesionService *services.SesionService) (*models.Jugador, bool, error) {
+	if token == "" {
+		return nil, false, nil
+	}
+	jugador, err := sesionService.Autenticar(token)
+	return jugador, true, err
+}
+
+// TokenBearer devuelve el token de la cabecera Authorization: Bearer, o "" si no lo hay
+func TokenBearer(c *gin.Context) string {
+	cabecera := c.GetHeader("Authorization")
+	if len(cabecera) > 7 && strings.EqualFold(cabecera[:7], "Bearer ") {
+		return strings.TrimSpace(cabecera[7:])
+	}
+	return ""
+}
+
+// JugadorActual devuelve el jugador autenticado por RequiereJugador, o nil si no lo hay
+func JugadorActual(c *gin.Context) *models.Jugador {
+	if valor, existe := c.Get(claveJugador); existe {
+		if jugador, ok := valor.(*models.Jugador); ok {
+			return jugador
+		}
+	}
+	return nil
+}
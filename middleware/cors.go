@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORS permite las peticiones del navegador desde los orígenes configurados.
+// Solo los orígenes de la lista reciben su propio origen de vuelta y pueden
+// enviar credenciales. Con "*" se admite cualquier otro origen, pero sin
+// credenciales. Sin orígenes no se añade ninguna cabecera
+func CORS(origenes []string) gin.HandlerFunc {
+	permitidos := make(map[string]bool, len(origenes))
+	for _, origen := range origenes {
+		permitidos[origen] = true
+	}
+
+	return func(c *gin.Context) {
+		origen := c.GetHeader("Origin")
+		if origen != "" && (permitidos["*"] || permitidos[origen]) {
+			if permitidos[origen] {
+				c.Header("Access-Control-Allow-Origin", origen)
+				c.Header("Access-Control-Allow-Credentials", "true")
+			} else {
+				c.Header("Access-Control-Allow-Origin", "*")
+			}
+			c.Header("Access-Control-Allow-Headers", "Authorization, Content-Type, Last-Event-ID")
+			c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+			c.Header("Access-Control-Expose-Headers", "X-QR-Code, X-QR-Secret, X-QR-Expires")
+			c.Header("Vary", "Origin")
+		}
+
+		// Las peticiones de comprobación previa no llegan a los handlers
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tokenEnRuta encuentra el token de acceso que los flujos en tiempo real reciben
+// en la URL (ver IdentificarEnFlujo)
+var tokenEnRuta = regexp.MustCompile(`([?&]access_token=)[^&]*`)
+
+// Registro es el registro de peticiones de gin con el mismo formato, pero sin el
+// token de acceso de la URL: quien lea los registros no debe poder usarlo
+func Registro() gin.HandlerFunc {
+	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
+		var statusColor, methodColor, resetColor string
+		if param.IsOutputColor() {
+			statusColor = param.StatusCodeColor()
+			methodColor = param.MethodColor()
+			resetColor = param.ResetColor()
+		}
+		if param.Latency > time.Minute {
+			param.Latency = param.Latency.Truncate(time.Second)
+		}
+		return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+			param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+			statusColor, param.StatusCode, resetColor,
+			param.Latency,
+			param.ClientIP,
+			methodColor, param.Method, resetColor,
+			ocultarToken(param.Path),
+			param.ErrorMessage,
+		)
+	})
+}
+
+// ocultarToken sustituye el valor de access_token en una ruta con consulta
+func ocultarToken(ruta string) string {
+	return tokenEnRuta.ReplaceAllString(ruta, "${1}oculto")
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// Canales del chat de una partida
+const (
+	CanalJugadores    = "jugadores"    // Entre los jugadores; lo pueden leer también los espectadores
+	CanalEspectadores = "espectadores" // Solo entre espectadores, para no dar pistas a los jugadores
+)
+
+// MensajeChat es un mensaje del chat de una partida
+type MensajeChat struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	PartidaID string    `gorm:"size:64;index:idx_mensaje_partida_canal" json:"partida_id"`
+	Canal     string    `gorm:"size:20;index:idx_mensaje_partida_canal" json:"canal"`
+	JugadorID uint      `json:"jugador_id"`
+	Nombre    string    `gorm:"size:100" json:"nombre"`
+	Texto     string    `gorm:"type:text" json:"texto"`
+	CreadoEn  time.Time `gorm:"column:creado_en" json:"creado_en"`
+}
+
+func (MensajeChat) TableName() string {
+	return "partida_mensajes"
+}
+
+// Silencio indica que un jugador no quiere ver los mensajes de otro en una partida
+type Silencio struct {
+	ID           uint   `gorm:"primaryKey"`
+	PartidaID    string `gorm:"size:64;uniqueIndex:idx_silencio"`
+	JugadorID    uint   `gorm:"uniqueIndex:idx_silencio"` // Quien silencia
+	SilenciadoID uint   `gorm:"uniqueIndex:idx_silencio"`
+}
+
+func (Silencio) TableName() string {
+	return "partida_silencios"
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+// Estados de un código QR de inicio de sesión
+const (
+	QRPendiente  = "pending"   // Generado, a la espera de que un móvil lo confirme
+	QRConfirmado = "confirmed" // Un jugador identificado lo ha confirmado
+	QRConsumido  = "consumed"  // Ya se ha usado para iniciar sesión
+	QRCaducado   = "expired"   // Ha pasado su tiempo de vida sin usarse
+)
+
+// CodigoQR es un código de inicio de sesión que muestra un dispositivo y
+// confirma otro en el que el jugador ya está identificado
+type CodigoQR struct {
+	ID           uint       `gorm:"primaryKey"`
+	Codigo       string     `gorm:"size:36;uniqueIndex"`
+	SecretoHash  string     `gorm:"size:64"` // Solo lo conoce el dispositivo que generó el código
+	Estado       string     `gorm:"size:20"`
+	JugadorID    *uint      // Jugador que lo confirmó
+	ExpiraEn     time.Time  `gorm:"column:expira_en;index"`
+	CreadoEn     time.Time  `gorm:"column:creado_en"`
+	ConfirmadoEn *time.Time `gorm:"column:confirmado_en"`
+}
+
+func (CodigoQR) TableName() string {
+	return "codigos_qr"
+}
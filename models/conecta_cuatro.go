@@ -1,9 +1,5 @@
 package models
 
-import (
-	"time"
-)
-
 type JugadorConectaCuatro struct {
 	Jugador          // Hereda de Jugador
 	Ficha     string `json:"ficha"`      // Ficha del jugador (X o O)
@@ -12,13 +8,18 @@ type JugadorConectaCuatro struct {
 }
 
 type ConectaCuatro struct {
-	ID          string       `json:"id"`
-	TipoJuego   string       `json:"tipo_juego"`
-	Jugadores   []Jugador    `json:"jugadores"`
-	Tablero     [6][7]string `json:"tablero"`
-	Estado      string       `json:"estado"`
-	CreadoEn    time.Time    `json:"creado_en"`
-	Actualizado time.Time    `json:"actualizado_en"`
-	Turno       int          `json:"turno"`            // 0 para el primer jugador, 1 para el segundo
-	Ganador     *Jugador     `json:"winner,omitempty"` // Jugador ganador (si existe)
+	Juego                  // Campos comunes (id, tipo, estado, fechas y ganador)
+	Jugadores []Jugador    `json:"jugadores"`
+	Tablero   [6][7]string `json:"tablero"`
+	Turno     int          `json:"turno"` // 0 para el primer jugador, 1 para el segundo
+}
+
+// Participantes devuelve los jugadores de la partida en orden de turno
+func (j *ConectaCuatro) Participantes() []Jugador {
+	return j.Jugadores
+}
+
+// Sentar ocupa un asiento libre con el jugador indicado
+func (j *ConectaCuatro) Sentar(asiento int, jugador Jugador) {
+	j.Jugadores[asiento] = jugador
 }
@@ -1,9 +1,5 @@
 package models
 
-import (
-	"time"
-)
-
 type JugadorCuatroEnRaya struct {
 	Jugador          // Hereda de Jugador
 	Ficha     string `json:"ficha"`      // Ficha del jugador (X o O)
@@ -13,13 +9,18 @@ type JugadorCuatroEnRaya struct {
 
 // CuatroEnRaya representa el estado del juego de cuatro en raya
 type CuatroEnRaya struct {
-	ID          string       `json:"id"`
-	TipoJuego   string       `json:"tipo_juego"`
-	Jugadores   []Jugador    `json:"jugadores"`
-	Tablero     [4][4]string `json:"tablero"`
-	Estado      string       `json:"estado"`
-	CreadoEn    time.Time    `json:"creado_en"`
-	Actualizado time.Time    `json:"actualizado_en"`
-	Turno       int          `json:"turno"`            // 0 para el primer jugador, 1 para el segundo
-	Ganador     *Jugador     `json:"winner,omitempty"` // Jugador ganador (si existe)
+	Juego                  // Campos comunes (id, tipo, estado, fechas y ganador)
+	Jugadores []Jugador    `json:"jugadores"`
+	Tablero   [4][4]string `json:"tablero"`
+	Turno     int          `json:"turno"` // 0 para el primer jugador, 1 para el segundo
+}
+
+// Participantes devuelve los jugadores de la partida en orden de turno
+func (j *CuatroEnRaya) Participantes() []Jugador {
+	return j.Jugadores
+}
+
+// Sentar ocupa un asiento libre con el jugador indicado
+func (j *CuatroEnRaya) Sentar(asiento int, jugador Jugador) {
+	j.Jugadores[asiento] = jugador
 }
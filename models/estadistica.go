@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+)
+
+// Puntuación Elo de los jugadores que aún no han terminado ninguna partida
+const PuntuacionInicial = 1500
+
+// Estadistica son los resultados y la puntuación Elo de un jugador en un tipo de
+// juego. Solo cuentan las partidas que terminan con ganador o en empate, no las abandonadas
+type Estadistica struct {
+	ID            uint       `gorm:"primaryKey" json:"-"`
+	JugadorID     uint       `gorm:"uniqueIndex:idx_estadistica_jugador_tipo" json:"jugador_id"`
+	TipoJuego     string     `gorm:"size:40;uniqueIndex:idx_estadistica_jugador_tipo;index:idx_estadistica_tipo_puntuacion,priority:1;index:idx_estadistica_tipo_victorias,priority:1" json:"tipo_juego"`
+	Jugadas       int        `json:"jugadas"`
+	Victorias     int        `gorm:"index:idx_estadistica_tipo_victorias,priority:2" json:"victorias"`
+	Derrotas      int        `json:"derrotas"`
+	Empates       int        `json:"empates"`
+	RachaActual   int        `json:"racha_actual"`            // Positiva si son victorias seguidas, negativa si son derrotas
+	MejorRacha    int        `json:"mejor_racha"`             // Más victorias seguidas
+	PeorRacha     int        `json:"peor_racha"`              // Más derrotas seguidas
+	DuracionTotal int64      `json:"-"`                       // Segundos sumados de todas las partidas
+	DuracionMedia float64    `gorm:"-" json:"duracion_media"` // Segundos por partida
+	UltimaPartida *time.Time `json:"ultima_partida,omitempty"`
+	Puntuacion    int        `gorm:"default:1500;index:idx_estadistica_tipo_puntuacion,priority:2" json:"puntuacion"`
+	Provisional   bool       `gorm:"-" json:"provisional"` // Aún no ha jugado bastantes partidas para fiarse de la puntuación
+}
+
+func (Estadistica) TableName() string {
+	return "jugador_estadisticas"
+}
+
+// HistorialPuntuacion es el resultado de un jugador en una partida terminada y
+// cómo cambió su puntuación. Sirve también para las clasificaciones por periodo
+type HistorialPuntuacion struct {
+	ID         uint      `gorm:"primaryKey" json:"-"`
+	JugadorID  uint      `gorm:"index:idx_historial_jugador_tipo" json:"jugador_id"`
+	TipoJuego  string    `gorm:"size:40;index:idx_historial_jugador_tipo;index:idx_historial_tipo_fecha" json:"tipo_juego"`
+	PartidaID  string    `gorm:"size:64" json:"partida_id"`
+	Resultado  string    `gorm:"size:20" json:"resultado"`
+	Anterior   int       `json:"anterior"`
+	Puntuacion int       `json:"puntuacion"`
+	CreadoEn   time.Time `gorm:"column:creado_en;index:idx_historial_tipo_fecha" json:"creado_en"`
+}
+
+func (HistorialPuntuacion) TableName() string {
+	return "jugador_puntuaciones"
+}
+
+// Criterios para ordenar una clasificación
+const (
+	OrdenPuntuacion = "puntuacion"
+	OrdenVictorias  = "victorias"
+)
+
+// PuestoClasificacion es un jugador en la clasificación de un tipo de juego. En
+// las clasificaciones por periodo, victorias y jugadas cuentan solo las de ese
+// periodo; la puntuación es siempre la actual
+type PuestoClasificacion struct {
+	Posicion   int    `json:"posicion"` // Los empatados comparten posición
+	JugadorID  uint   `json:"jugador_id"`
+	Nombre     string `json:"nombre"`
+	Puntuacion int    `json:"puntuacion"`
+	Victorias  int    `json:"victorias"`
+	Jugadas    int    `json:"jugadas"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// Estados posibles de una partida
+const (
+	EstadoEsperando  = "Esperando Jugadores" // Quedan asientos libres; aún no se puede mover
+	EstadoEnProgreso = "En Progreso"
+	EstadoTerminado  = "Terminado"
+	EstadoEmpate     = "Empate"
+	EstadoAbandonado = "Abandonado"
+)
+
+// Juego agrupa los campos comunes a todos los tipos de juego
+type Juego struct {
+	ID          string    `json:"id"`
+	TipoJuego   string    `json:"tipo_juego"`
+	Codigo      string    `json:"codigo,omitempty"` // Código corto para invitar a la partida
+	Sala        *Sala     `json:"sala,omitempty"`   // Ajustes si la partida se creó desde el lobby
+	Reloj       *Reloj    `json:"reloj,omitempty"`  // Tiempo de cada asiento si la sala tiene control de tiempo
+	Estado      string    `json:"estado"`
+	CreadoEn    time.Time `json:"creado_en"`
+	Actualizado time.Time `json:"actualizado_en"`
+	Ganador     *Jugador  `json:"winner,omitempty"` // Jugador ganador (si existe)
+}
+
+// AsientoLibre indica si un jugador de la partida es en realidad un asiento por ocupar
+func AsientoLibre(jugador Jugador) bool {
+	return jugador.ID == 0
+}
+
+// Comun devuelve los campos comunes de la partida (lo heredan todos los juegos)
+func (j *Juego) Comun() *Juego {
+	return j
+}
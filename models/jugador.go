@@ -5,6 +5,7 @@ type Jugador struct {
     Name     string `gorm:"column:Name" json:"name"`
     Email    string `gorm:"column:Email" json:"email"`
     Password string `gorm:"column:Password" json:"-"`
+    Bot      bool   `gorm:"column:bot;default:false" json:"bot,omitempty"` // Jugador automático del servidor
 }
 func (Jugador) TableName() string {
 	return "jugador"
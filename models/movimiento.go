@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// Tipos de entrada del historial de una partida
+const (
+	MovimientoJugada   = "jugada"   // Movimiento normal de un jugador
+	MovimientoReinicio = "reinicio" // La partida se reinició; Datos guarda el estado completo resultante
+	MovimientoUnion    = "union"    // Un jugador ocupó un asiento libre; Datos guarda el estado completo resultante
+)
+
+// Movimiento es una entrada del historial ordenado de una partida
+type Movimiento struct {
+	ID         uint      `gorm:"primaryKey" json:"-"`
+	PartidaID  string    `gorm:"size:64;uniqueIndex:idx_movimiento_numero" json:"partida_id"`
+	Numero     int       `gorm:"uniqueIndex:idx_movimiento_numero" json:"numero"` // Empieza en 1
+	Tipo       string    `gorm:"size:20" json:"tipo"`
+	Asiento    int       `json:"asiento"`    // -1 si no se sabe quién movió
+	JugadorID  uint      `json:"jugador_id"` // 0 si no se sabe quién movió
+	Datos      string    `gorm:"type:text" json:"datos"`
+	HashEstado string    `gorm:"size:64" json:"hash_estado"` // SHA-256 del estado tras el movimiento
+	CreadoEn   time.Time `gorm:"column:creado_en" json:"creado_en"`
+}
+
+func (Movimiento) TableName() string {
+	return "partida_movimientos"
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+)
+
+// Resultados de un participante al terminar la partida
+const (
+	ResultadoVictoria = "victoria"
+	ResultadoDerrota  = "derrota"
+	ResultadoEmpate   = "empate"
+)
+
+// Partida es el registro persistente de una partida de cualquier tipo de juego
+type Partida struct {
+	ID            string         `gorm:"primaryKey;size:64" json:"id"`
+	TipoJuego     string         `gorm:"size:40;index" json:"tipo_juego"`
+	Codigo        *string        `gorm:"size:12;uniqueIndex" json:"codigo,omitempty"` // Código para unirse a la partida
+	Estado        string         `gorm:"size:40;index" json:"estado"`
+	GanadorID     *uint          `json:"ganador_id,omitempty"`
+	CreadoEn      time.Time      `gorm:"column:creado_en" json:"creado_en"`
+	Actualizado   time.Time      `gorm:"column:actualizado_en" json:"actualizado_en"`
+	TerminadoEn   *time.Time     `gorm:"column:terminado_en;index" json:"terminado_en,omitempty"`
+	Movimientos   int            `gorm:"column:num_movimientos" json:"num_movimientos"`
+	Sala          Sala           `gorm:"embedded;embeddedPrefix:sala_" json:"sala"`
+	Participantes []Participante `gorm:"foreignKey:PartidaID" json:"participantes,omitempty"`
+	Tablero       TableroPartida `gorm:"foreignKey:PartidaID" json:"-"`
+}
+
+func (Partida) TableName() string {
+	return "partidas"
+}
+
+// Participante es un jugador sentado en una partida
+type Participante struct {
+	ID        uint   `gorm:"primaryKey" json:"-"`
+	PartidaID string `gorm:"size:64;uniqueIndex:idx_participante_asiento" json:"partida_id"`
+	Asiento   int    `gorm:"uniqueIndex:idx_participante_asiento" json:"asiento"`
+	JugadorID uint   `gorm:"index" json:"jugador_id"` // 0 mientras el asiento está libre
+	Nombre    string `gorm:"size:100" json:"nombre"`
+	Resultado string `gorm:"size:20" json:"resultado,omitempty"` // Vacío mientras la partida sigue en curso
+}
+
+func (Participante) TableName() string {
+	return "partida_participantes"
+}
+
+// TableroPartida guarda el estado completo del juego (tablero, turno, bolas...) en JSON.
+// Inicial es el estado al crear la partida, del que parte la repetición
+type TableroPartida struct {
+	PartidaID string `gorm:"primaryKey;size:64"`
+	Datos     string `gorm:"type:text"`
+	Inicial   string `gorm:"type:text"`
+}
+
+func (TableroPartida) TableName() string {
+	return "partida_tableros"
+}
@@ -11,13 +11,28 @@ type JugadorPasaBolas struct {
 
 // PasaBolas representa el estado del juego Pasa Bolas
 type PasaBolas struct {
-	ID           string             `json:"id"`           // ID único del juego
-	TipoJuego    string             `json:"tipo_juego"`   // Tipo de juego
+	Juego                           // Campos comunes (id, tipo, estado, fechas y ganador)
 	Jugadores    []JugadorPasaBolas `json:"jugadores"`    // Lista de jugadores
-	Estado       string             `json:"estado"`       // Estado del juego
 	Ciclos       int                `json:"ciclos"`       // Número de ciclos
 	Temporizador int                `json:"temporizador"` // Tiempo de ciclo en segundos
 }
+
+// Participantes devuelve los jugadores de la partida según su posición en la mesa
+func (j *PasaBolas) Participantes() []Jugador {
+	jugadores := make([]Jugador, len(j.Jugadores))
+	for i, jugador := range j.Jugadores {
+		jugadores[i] = jugador.Jugador
+	}
+	return jugadores
+}
+
+// Sentar ocupa un asiento libre; las bolas repartidas a ese asiento pasan a ser del jugador
+func (j *PasaBolas) Sentar(asiento int, jugador Jugador) {
+	j.Jugadores[asiento].Jugador = jugador
+	for i := range j.Jugadores[asiento].Bolas {
+		j.Jugadores[asiento].Bolas[i].PlayerID = jugador.ID
+	}
+}
  type Bola struct {
 	X         float64 `json:"x"`
 	Y         float64 `json:"y"`
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Sala son los ajustes de una partida creada desde el lobby. Mientras tenga
+// asientos libres la partida está en EstadoEsperando y las salas públicas
+// aparecen en el listado del lobby
+type Sala struct {
+	AnfitrionID   uint   `gorm:"column:anfitrion_id" json:"anfitrion_id"`
+	Privada       bool   `gorm:"column:privada;index" json:"privada"` // Solo se entra con el código de invitación
+	Variante      string `gorm:"column:variante;size:40" json:"variante,omitempty"`
+	TiempoInicial int    `gorm:"column:tiempo_inicial" json:"tiempo_inicial,omitempty"` // Segundos por jugador; 0 sin reloj
+	Incremento    int    `gorm:"column:incremento" json:"incremento,omitempty"`         // Segundos que se suman por movimiento
+}
+
+// Reloj es el tiempo que le queda a cada asiento en las partidas con control de
+// tiempo. Solo corre el del asiento que tiene el turno, desde Desde
+type Reloj struct {
+	Restante   []int64   `json:"restante"`   // Milisegundos que le quedan a cada asiento sin contar el turno en curso
+	Incremento int64     `json:"incremento"` // Milisegundos que se suman tras cada movimiento
+	Desde      time.Time `json:"desde"`      // Cuándo empezó el turno en curso; cero con el reloj parado
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// Sesion es una sesión iniciada por un jugador. Solo se guarda el hash de los
+// tokens, nunca los tokens en claro
+type Sesion struct {
+	ID             uint      `gorm:"primaryKey"`
+	JugadorID      uint      `gorm:"index"`
+	AccesoHash     string    `gorm:"size:64;uniqueIndex"`
+	RefrescoHash   string    `gorm:"size:64;uniqueIndex"`
+	AccesoExpira   time.Time `gorm:"column:acceso_expira"`
+	RefrescoExpira time.Time `gorm:"column:refresco_expira;index"`
+	CreadoEn       time.Time `gorm:"column:creado_en"`
+}
+
+func (Sesion) TableName() string {
+	return "sesiones"
+}
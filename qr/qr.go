@@ -0,0 +1,131 @@
+// Package qr dibuja códigos QR como imágenes PNG o SVG para los clientes que
+// no pueden generarlos por su cuenta (quioscos, televisores...)
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// Formatos de imagen disponibles
+const (
+	FormatoPNG = "png"
+	FormatoSVG = "svg"
+)
+
+// Límites del tamaño de la imagen en píxeles
+const (
+	TamanoPorDefecto = 256
+	TamanoMinimo     = 64
+	TamanoMaximo     = 2048
+)
+
+var (
+	ErrFormato = errors.New("Formato de imagen no válido (png o svg)")
+	ErrTamano  = fmt.Errorf("El tamaño debe estar entre %d y %d píxeles", TamanoMinimo, TamanoMaximo)
+	ErrNivel   = errors.New("Nivel de corrección de errores no válido (L, M, Q o H)")
+)
+
+// niveles de corrección de errores: cuanto más alto, más daño soporta el QR y más denso es
+var niveles = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+// Opciones indican cómo dibujar el QR
+type Opciones struct {
+	Formato string // png o svg
+	Tamano  int    // Lado de la imagen en píxeles
+	Nivel   string // Corrección de errores: L, M, Q o H
+}
+
+// Validar rellena los valores por defecto y comprueba las opciones
+func (o *Opciones) Validar() error {
+	o.Formato = strings.ToLower(o.Formato)
+	if o.Formato != FormatoPNG && o.Formato != FormatoSVG {
+		return ErrFormato
+	}
+	if o.Tamano == 0 {
+		o.Tamano = TamanoPorDefecto
+	}
+	if o.Tamano < TamanoMinimo || o.Tamano > TamanoMaximo {
+		return ErrTamano
+	}
+	if o.Nivel == "" {
+		o.Nivel = "M"
+	}
+	o.Nivel = strings.ToUpper(o.Nivel)
+	if _, existe := niveles[o.Nivel]; !existe {
+		return ErrNivel
+	}
+	return nil
+}
+
+// LeerOpciones interpreta las opciones tal como llegan en la URL
+// (?formato=svg&tamano=300&nivel=H) y las valida
+func LeerOpciones(formato, tamano, nivel string) (Opciones, error) {
+	opciones := Opciones{Formato: formato, Nivel: nivel}
+	if tamano != "" {
+		valor, err := strconv.Atoi(tamano)
+		if err != nil {
+			return opciones, ErrTamano
+		}
+		opciones.Tamano = valor
+	}
+	return opciones, opciones.Validar()
+}
+
+// TipoContenido devuelve el Content-Type de la imagen
+func (o Opciones) TipoContenido() string {
+	if o.Formato == FormatoSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// Dibujar codifica el contenido en un QR y lo devuelve como imagen
+func Dibujar(contenido string, opciones Opciones) ([]byte, error) {
+	if err := opciones.Validar(); err != nil {
+		return nil, err
+	}
+
+	codigo, err := qrcode.New(contenido, niveles[opciones.Nivel])
+	if err != nil {
+		return nil, err
+	}
+
+	if opciones.Formato == FormatoSVG {
+		return svg(codigo.Bitmap(), opciones.Tamano), nil
+	}
+	return codigo.PNG(opciones.Tamano)
+}
+
+// svg dibuja la matriz del QR (con su margen incluido) como un único trazado.
+// Cada fila se recorre juntando los módulos oscuros seguidos en un rectángulo
+func svg(modulos [][]bool, tamano int) []byte {
+	lado := len(modulos)
+	var trazado strings.Builder
+	for y, fila := range modulos {
+		for x := 0; x < lado; x++ {
+			if !fila[x] {
+				continue
+			}
+			inicio := x
+			for x < lado && fila[x] {
+				x++
+			}
+			fmt.Fprintf(&trazado, "M%d %dh%dv1h-%dz", inicio, y, x-inicio, x-inicio)
+		}
+	}
+
+	return []byte(fmt.Sprintf(
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+
+			`<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="%s"/></svg>`,
+		tamano, tamano, lado, lado, lado, lado, trazado.String()))
+}
@@ -0,0 +1,251 @@
+// Package realtime reparte los cambios de cada partida entre los clientes
+// conectados (jugadores y espectadores) sin que tengan que consultar el estado
+package realtime
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Tipos de evento que se envían a los clientes
+const (
+	EventoEstado     = "estado"     // Estado completo de la partida, al conectarse
+	EventoMovimiento = "movimiento" // Un jugador ha movido
+	EventoTurno      = "turno"      // Cambia el jugador que tiene el turno
+	EventoFin        = "fin"        // La partida ha terminado
+	EventoReinicio   = "reinicio"   // La partida ha vuelto a empezar
+	EventoUnion      = "union"      // Un jugador ha ocupado un asiento libre
+
+	EventoEspectadores = "espectadores" // Ha cambiado la lista de espectadores
+	EventoChat         = "chat"         // Mensaje nuevo en el chat de la partida
+
+	EventoEmparejado = "emparejado"  // Aviso a un jugador: el emparejamiento le ha creado una partida
+	EventoSinPartida = "sin_partida" // Aviso a un jugador: no se pudo crear su partida y ha salido de la cola
+)
+
+// tamañoBuffer es cuántos eventos puede acumular un cliente lento antes de desconectarlo
+const tamañoBuffer = 32
+
+// SinNumero es el Numero de los eventos que no siguen a ningún movimiento
+const SinNumero = -1
+
+// Evento es un cambio en una partida
+type Evento struct {
+	Tipo      string      `json:"tipo"`
+	PartidaID string      `json:"partida_id"`
+	Numero    int         `json:"numero"` // Número de movimiento tras el que se produce el evento, o SinNumero
+	Datos     interface{} `json:"datos"`
+}
+
+// Espectador es quien sigue una partida sin jugarla; JugadorID es 0 si no se ha identificado
+type Espectador struct {
+	JugadorID uint   `json:"jugador_id"`
+	Nombre    string `json:"nombre"`
+}
+
+// Espectadores es la lista de quienes miran una partida en este momento
+type Espectadores struct {
+	Total         int          `json:"total"`
+	Identificados []Espectador `json:"identificados"` // Espectadores con sesión iniciada
+	Anonimos      int          `json:"anonimos"`
+}
+
+// Oyente describe a quien está al otro lado de una suscripción, para decidir
+// qué eventos le llegan
+type Oyente struct {
+	JugadorID  uint // 0 si no se ha identificado
+	Espectador bool // No juega la partida
+}
+
+// Suscripcion recibe los eventos de una partida por el canal Eventos; el canal
+// se cierra al cancelar la suscripción o si el cliente no da abasto
+type Suscripcion struct {
+	Eventos    chan Evento
+	hub        *Hub
+	partidaID  string
+	jugadorID  uint        // Jugador identificado que sigue la partida, 0 si es anónimo
+	espectador *Espectador // nil si quien sigue la partida es uno de sus jugadores
+	cerrada    bool
+}
+
+// Hub guarda las suscripciones de cada partida y los avisos personales de cada
+// jugador, que van por un canal propio que no coincide con ningún ID de partida
+type Hub struct {
+	mutex         sync.Mutex
+	suscripciones map[string]map[*Suscripcion]bool
+}
+
+func NewHub() *Hub {
+	return &Hub{suscripciones: make(map[string]map[*Suscripcion]bool)}
+}
+
+// Suscribir empieza a recibir los eventos de una partida
+func (h *Hub) Suscribir(partidaID string) *Suscripcion {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.suscribir(partidaID, 0, nil)
+}
+
+// SuscribirParticipante empieza a recibir los eventos de una partida como uno de sus jugadores
+func (h *Hub) SuscribirParticipante(partidaID string, jugadorID uint) *Suscripcion {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.suscribir(partidaID, jugadorID, nil)
+}
+
+// SuscribirEspectador empieza a recibir los eventos de una partida como
+// espectador; los demás suscritos reciben la lista de espectadores actualizada
+func (h *Hub) SuscribirEspectador(partidaID string, espectador Espectador) *Suscripcion {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	suscripcion := h.suscribir(partidaID, espectador.JugadorID, &espectador)
+	h.avisarEspectadores(partidaID)
+	return suscripcion
+}
+
+// SuscribirJugador empieza a recibir los avisos dirigidos a un jugador
+func (h *Hub) SuscribirJugador(jugadorID uint) *Suscripcion {
+	return h.Suscribir(canalJugador(jugadorID))
+}
+
+// Cancelar deja de recibir eventos y cierra el canal
+func (s *Suscripcion) Cancelar() {
+	s.hub.mutex.Lock()
+	defer s.hub.mutex.Unlock()
+	if s.hub.quitar(s) {
+		s.hub.avisarEspectadores(s.partidaID)
+	}
+}
+
+// Publicar envía un evento a todos los suscritos a la partida sin bloquearse
+func (h *Hub) Publicar(evento Evento) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.enviar(evento.PartidaID, evento, nil)
+}
+
+// PublicarA envía un evento solo a los suscritos a la partida que cumplen la condición
+func (h *Hub) PublicarA(evento Evento, destinatario func(Oyente) bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.enviar(evento.PartidaID, evento, destinatario)
+}
+
+// Avisar envía un evento solo a las conexiones de un jugador
+func (h *Hub) Avisar(jugadorID uint, evento Evento) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.enviar(canalJugador(jugadorID), evento, nil)
+}
+
+// Suscritos devuelve cuántos clientes siguen una partida
+func (h *Hub) Suscritos(partidaID string) int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return len(h.suscripciones[partidaID])
+}
+
+// Espectadores devuelve quiénes miran la partida sin jugarla
+func (h *Hub) Espectadores(partidaID string) Espectadores {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.espectadores(partidaID)
+}
+
+// suscribir da de alta una suscripción; requiere el mutex
+func (h *Hub) suscribir(canal string, jugadorID uint, espectador *Espectador) *Suscripcion {
+	suscripcion := &Suscripcion{
+		Eventos:    make(chan Evento, tamañoBuffer),
+		hub:        h,
+		partidaID:  canal,
+		jugadorID:  jugadorID,
+		espectador: espectador,
+	}
+	if h.suscripciones[canal] == nil {
+		h.suscripciones[canal] = make(map[*Suscripcion]bool)
+	}
+	h.suscripciones[canal][suscripcion] = true
+	return suscripcion
+}
+
+// enviar reparte un evento entre los suscritos a un canal sin bloquearse; con
+// destinatario solo a los que cumplen la condición. Requiere el mutex
+func (h *Hub) enviar(canal string, evento Evento, destinatario func(Oyente) bool) {
+	var lentos []*Suscripcion
+	for suscripcion := range h.suscripciones[canal] {
+		oyente := Oyente{JugadorID: suscripcion.jugadorID, Espectador: suscripcion.espectador != nil}
+		if destinatario != nil && !destinatario(oyente) {
+			continue
+		}
+		select {
+		case suscripcion.Eventos <- evento:
+		default:
+			// El cliente no consume: se le desconecta para que vuelva a conectarse
+			lentos = append(lentos, suscripcion)
+		}
+	}
+
+	quitadoEspectador := false
+	for _, suscripcion := range lentos {
+		if h.quitar(suscripcion) {
+			quitadoEspectador = true
+		}
+	}
+	if quitadoEspectador {
+		h.avisarEspectadores(canal)
+	}
+}
+
+// avisarEspectadores envía a la partida su lista de espectadores; requiere el mutex
+func (h *Hub) avisarEspectadores(partidaID string) {
+	h.enviar(partidaID, Evento{
+		Tipo:      EventoEspectadores,
+		PartidaID: partidaID,
+		Numero:    SinNumero,
+		Datos:     h.espectadores(partidaID),
+	}, nil)
+}
+
+// espectadores cuenta los espectadores de una partida; un jugador con varias
+// conexiones abiertas cuenta una sola vez. Requiere el mutex
+func (h *Hub) espectadores(partidaID string) Espectadores {
+	lista := Espectadores{Identificados: []Espectador{}}
+	vistos := make(map[uint]bool)
+	for suscripcion := range h.suscripciones[partidaID] {
+		espectador := suscripcion.espectador
+		switch {
+		case espectador == nil, vistos[espectador.JugadorID]:
+			continue
+		case espectador.JugadorID == 0:
+			lista.Anonimos++
+		default:
+			vistos[espectador.JugadorID] = true
+			lista.Identificados = append(lista.Identificados, *espectador)
+		}
+		lista.Total++
+	}
+	sort.Slice(lista.Identificados, func(i, j int) bool {
+		return lista.Identificados[i].JugadorID < lista.Identificados[j].JugadorID
+	})
+	return lista
+}
+
+// canalJugador es el canal de los avisos personales de un jugador
+func canalJugador(jugadorID uint) string {
+	return fmt.Sprintf("jugador:%d", jugadorID)
+}
+
+// quitar elimina una suscripción e indica si era de un espectador; requiere el mutex
+func (h *Hub) quitar(s *Suscripcion) bool {
+	if s.cerrada {
+		return false
+	}
+	s.cerrada = true
+	close(s.Eventos)
+	delete(h.suscripciones[s.partidaID], s)
+	if len(h.suscripciones[s.partidaID]) == 0 {
+		delete(h.suscripciones, s.partidaID)
+	}
+	return s.espectador != nil
+}
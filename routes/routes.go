@@ -2,28 +2,33 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"juego/config"
 	"juego/controllers"
-	"juego/db"
+	"juego/engine"
 	"juego/handlers"
+	"juego/mcts"
+	"juego/middleware"
+	"juego/realtime"
 	"juego/services"
+	"juego/store"
+	"time"
 )
 
-func SetupRouter() *gin.Engine {
-	r := gin.Default()
+func SetupRouter(cfg *config.Config, gameStore store.GameStore) *gin.Engine {
+	gin.SetMode(cfg.ModoGin)
+	r := gin.New()
+	r.Use(middleware.Registro(), gin.Recovery())
+	r.Use(middleware.CORS(cfg.CORSOrigenes))
 
 	// Crear una instancia del JugadorService
-	// Asegúrate de que db.DB sea del tipo *gorm.io/gorm.DB
-	jugadorService := services.NewJugadorService(db.DB)
+	jugadorService := services.NewJugadorService(gameStore)
+	sesionService := services.NewSesionService(gameStore, time.Duration(cfg.Sesiones.DuracionAcceso), time.Duration(cfg.Sesiones.DuracionRefresco))
 
-	// Nuevo controlador QR
-	qrController := controllers.NewQRController()
+	// Estadísticas y puntuaciones de cada jugador por tipo de juego, actualizadas al terminar cada partida
+	estadisticaService := services.NewEstadisticaService(gameStore)
 
-	// Rutas QR
-	r.POST("/generate-qr", qrController.GenerateQR)
-	r.POST("/check-qr-status", qrController.CheckQRStatus)
-	r.POST("/login/qr", qrController.LoginWithQR)
 	// Crear una instancia del JugadorController
-	jugadorController := controllers.NewJugadorController(jugadorService)
+	jugadorController := controllers.NewJugadorController(jugadorService, sesionService, estadisticaService)
 
 	// Ruta para el registro
 	r.POST("/register", jugadorController.Register)
@@ -31,30 +36,124 @@ func SetupRouter() *gin.Engine {
 	// Ruta para iniciar sesión
 	r.POST("/login", jugadorController.Login)
 
+	// Exige un jugador identificado con el token de /login
+	requiereJugador := middleware.RequiereJugador(sesionService)
+	// Identifica al jugador si manda credenciales, pero admite visitantes anónimos
+	identificarJugador := middleware.IdentificarJugador(sesionService)
+	// Lo mismo para WebSocket y Server-Sent Events, que también admiten el token en ?access_token=
+	requiereJugadorEnFlujo := middleware.RequiereJugadorEnFlujo(sesionService)
+	identificarEnFlujo := middleware.IdentificarEnFlujo(sesionService)
+
+	// Renovación y cierre de sesión
+	r.POST("/refresh", jugadorController.Refresh)
+	r.POST("/logout", requiereJugador, jugadorController.Logout)
+
+	// Inicio de sesión con QR: el móvil identificado confirma el código que muestra otro dispositivo
+	qrService := services.NewQRService(gameStore, sesionService, time.Duration(cfg.Sesiones.DuracionQR))
+	qrController := controllers.NewQRController(qrService, estadisticaService)
+	r.POST("/generate-qr", qrController.GenerateQR)
+	r.POST("/check-qr-status", qrController.CheckQRStatus)
+	r.POST("/confirm-qr", requiereJugador, qrController.ConfirmQR)
+	r.POST("/login/qr", qrController.LoginWithQR)
+
+	// Capa HTTP común a todos los juegos
+	hub := realtime.NewHub()
+	juegoService := services.NewJuegoService(gameStore, hub, time.Duration(cfg.Juegos.TiempoInactividad), mcts.Config{
+		Iteraciones: cfg.Montecarlo.Iteraciones,
+		Tiempo:      time.Duration(cfg.Montecarlo.Tiempo),
+		Semilla:     cfg.Montecarlo.Semilla,
+	})
+	juegoHandler := handlers.NewJuegoHandler(juegoService)
+	tiempoRealHandler := handlers.NewTiempoRealHandler(juegoService, cfg.CORSOrigenes)
+
+	// Rutas genéricas: /games/:type admite cualquier juego registrado
+	r.GET("/games", juegoHandler.Tipos)
+	r.POST("/games/:type", requiereJugador, juegoHandler.CrearJuego)
+	r.GET("/games/:type/:id", juegoHandler.ObtenerJuego)
+	r.POST("/games/:type/:id/movimiento", requiereJugador, juegoHandler.HacerMovimiento)
+	r.GET("/games/:type/:id/replay", juegoHandler.RepetirJuego)
+	r.GET("/games/:type/:id/movimientos-legales", juegoHandler.MovimientosLegales)
+	r.GET("/games/:type/:id/pista", requiereJugador, juegoHandler.Pista)
+	r.POST("/games/:type/:id/reiniciar", requiereJugador, juegoHandler.ReiniciarJuego)
+	r.POST("/games/:type/:id/terminar", requiereJugador, juegoHandler.TerminarJuego)
+
+	// Invitaciones: código corto, enlace y QR para ocupar los asientos libres
+	invitacionHandler := handlers.NewInvitacionHandler(juegoService, cfg.Juegos.EnlaceInvitacion)
+	r.GET("/games/:type/:id/invitacion", invitacionHandler.Invitacion)
+	r.POST("/unirse/:codigo", requiereJugador, invitacionHandler.Unirse)
+
+	// Lobby: salas con ajustes que empiezan al ocuparse todos los asientos
+	salaHandler := handlers.NewSalaHandler(juegoService)
+	r.GET("/salas", salaHandler.ListarSalas)
+	r.POST("/salas/:type", requiereJugador, salaHandler.CrearSala)
+	r.DELETE("/salas/:type/:id", requiereJugador, salaHandler.CerrarSala)
+
+	// Emparejamiento: colas por tipo de juego que crean la partida al juntar a los jugadores
+	emparejamientoService := services.NewEmparejamientoService(juegoService, hub,
+		cfg.Emparejamiento.RangoInicial, cfg.Emparejamiento.AmpliacionRango, time.Duration(cfg.Emparejamiento.IntervaloAmpliacion))
+	emparejamientoService.Puntuacion = estadisticaService.Puntuacion
+	emparejamientoService.Iniciar()
+	emparejamientoHandler := handlers.NewEmparejamientoHandler(emparejamientoService, hub)
+	r.GET("/emparejamiento", requiereJugador, emparejamientoHandler.Estado)
+	r.DELETE("/emparejamiento", requiereJugador, emparejamientoHandler.Cancelar)
+	r.GET("/emparejamiento/eventos", requiereJugadorEnFlujo, emparejamientoHandler.Eventos)
+	r.POST("/emparejamiento/:type", requiereJugador, emparejamientoHandler.Buscar)
+
+	// Cambios de la partida en tiempo real para jugadores y espectadores
+	r.GET("/games/:type/:id/ws", identificarEnFlujo, tiempoRealHandler.WebSocket)
+	r.GET("/games/:type/:id/eventos", identificarEnFlujo, tiempoRealHandler.Eventos)
+	r.GET("/games/:type/:id/espectadores", juegoHandler.Espectadores)
+
+	// Chat de la partida: canal de jugadores y canal aparte para los espectadores
+	chatService := services.NewChatService(juegoService, cfg.Chat.LongitudMaxima, services.NewFiltroPalabras(cfg.Chat.PalabrasProhibidas))
+	if cfg.Chat.MensajesPorVentana > 0 {
+		chatService.Moderadores = append(chatService.Moderadores, services.NewLimiteMensajes(cfg.Chat.MensajesPorVentana, time.Duration(cfg.Chat.Ventana)))
+	}
+	chatHandler := handlers.NewChatHandler(chatService)
+	r.GET("/games/:type/:id/chat", identificarJugador, chatHandler.Historial)
+	r.POST("/games/:type/:id/chat", requiereJugador, chatHandler.Enviar)
+	r.POST("/games/:type/:id/silenciar", requiereJugador, chatHandler.Silenciar)
+	r.DELETE("/games/:type/:id/silenciar", requiereJugador, chatHandler.QuitarSilencio)
+
+	// Perfil, estadísticas y puntuaciones de cada jugador
+	jugadorHandler := handlers.NewJugadorHandler(estadisticaService)
+	r.GET("/jugadores/:id", jugadorHandler.Jugador)
+	r.GET("/jugadores/:id/estadisticas", jugadorHandler.Estadisticas)
+	r.GET("/jugadores/:id/partidas", jugadorHandler.Partidas)
+	r.GET("/jugadores/:id/puntuaciones/:type", jugadorHandler.HistorialPuntuacion)
+
+	// Clasificaciones por puntuación o victorias, de siempre, del mes o de la semana
+	clasificacionHandler := handlers.NewClasificacionHandler(estadisticaService)
+	r.GET("/leaderboards/:type", identificarJugador, clasificacionHandler.Clasificacion)
+
 	// Rutas para el juego Cuatro en Raya
-	r.POST("/crear-cuatro-en-raya", handlers.CrearJuego)
-	r.GET("/obtener-cuatro-en-raya/:id", handlers.ObtenerJuego)
-	r.POST("/movimiento-cuatro-en-raya/:id", handlers.HacerMovimiento)
-	r.POST("/terminar-cuatro-en-raya/:id", handlers.TerminarJuego)
+	cuatroEnRaya := engine.TipoCuatroEnRaya
+	r.POST("/crear-cuatro-en-raya", requiereJugador, handlers.ConTipo(cuatroEnRaya, juegoHandler.CrearJuego))
+	r.GET("/obtener-cuatro-en-raya/:id", handlers.ConTipo(cuatroEnRaya, juegoHandler.ObtenerJuego))
+	r.POST("/movimiento-cuatro-en-raya/:id", requiereJugador, handlers.ConTipo(cuatroEnRaya, juegoHandler.HacerMovimiento))
+	r.POST("/terminar-cuatro-en-raya/:id", requiereJugador, handlers.ConTipo(cuatroEnRaya, juegoHandler.TerminarJuego))
 
 	// Rutas para el juego conecta Cuatro
-	r.POST("/crear-conecta-cuatro", handlers.CrearJuegoConecta)
-	r.GET("/obtener-conecta-cuatro/:id", handlers.ObtenerJuegoConecta)
-	r.POST("/movimiento-conecta-cuatro/:id", handlers.HacerMovimientoConecta)
-	r.POST("/terminar-conecta-cuatro/:id", handlers.TerminarJuegoConecta)
+	conectaCuatro := engine.TipoConectaCuatro
+	r.POST("/crear-conecta-cuatro", requiereJugador, handlers.ConTipo(conectaCuatro, juegoHandler.CrearJuego))
+	r.GET("/obtener-conecta-cuatro/:id", handlers.ConTipo(conectaCuatro, juegoHandler.ObtenerJuego))
+	r.POST("/movimiento-conecta-cuatro/:id", requiereJugador, handlers.ConTipo(conectaCuatro, juegoHandler.HacerMovimiento))
+	r.POST("/terminar-conecta-cuatro/:id", requiereJugador, handlers.ConTipo(conectaCuatro, juegoHandler.TerminarJuego))
 
 	// Rutas para el juego Desde el borde
-	r.POST("/crear-desde-borde", handlers.CrearJuegoDesdeBorde)
-	r.GET("/obtener-desde-borde/:id", handlers.ObtenerJuegoDesdeBorde)
-	r.POST("/movimiento-desde-borde/:id", handlers.HacerMovimientoDesdeBorde)
-	r.POST("/terminar-desde-borde/:id", handlers.TerminarJuegoDesdeBorde)
+	desdeBorde := engine.TipoDesdeElBorde
+	r.POST("/crear-desde-borde", requiereJugador, handlers.ConTipo(desdeBorde, juegoHandler.CrearJuego))
+	r.GET("/obtener-desde-borde/:id", handlers.ConTipo(desdeBorde, juegoHandler.ObtenerJuego))
+	r.POST("/movimiento-desde-borde/:id", requiereJugador, handlers.ConTipo(desdeBorde, juegoHandler.HacerMovimiento))
+	r.POST("/terminar-desde-borde/:id", requiereJugador, handlers.ConTipo(desdeBorde, juegoHandler.TerminarJuego))
 
 	// Rutas para el juego Pasa Bolas
-	r.POST("/crear-juego-pasa-bolas", handlers.CrearJuegoPasaBolas)
-	r.GET("/obtener-juego-pasa-bolas/:id", handlers.ObtenerJuegoPasaBolas)
-	r.POST("/lanza-bola-pasa-bolas/:id", handlers.LanzarBola)
-	r.POST("/terminar-juego-pasa-bolas/:id", handlers.TerminarJuegoPasaBolas)
-	r.POST("/reiniciar-juego-pasa-bolas/:id", handlers.ReiniciarBolasPasaBolas)
+	pasaBolas := engine.TipoPasaBolas
+	r.POST("/crear-juego-pasa-bolas", requiereJugador, handlers.ConTipo(pasaBolas, juegoHandler.CrearJuego))
+	r.GET("/obtener-juego-pasa-bolas/:id", handlers.ConTipo(pasaBolas, juegoHandler.ObtenerJuego))
+	r.POST("/lanza-bola-pasa-bolas/:id", requiereJugador, handlers.ConTipo(pasaBolas, juegoHandler.HacerMovimiento))
+	r.POST("/terminar-juego-pasa-bolas/:id", requiereJugador, handlers.ConTipo(pasaBolas, juegoHandler.TerminarJuego))
+	r.POST("/reiniciar-juego-pasa-bolas/:id", requiereJugador, handlers.ConTipo(pasaBolas, juegoHandler.ReiniciarJuego))
 
 	return r
 }
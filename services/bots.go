@@ -0,0 +1,203 @@
+package services
+
+import (
+	"errors"
+	"juego/engine"
+	"juego/ia"
+	"juego/models"
+	"juego/store"
+	"log"
+	"strings"
+)
+
+// dominioBots es el dominio de correo de los jugadores automáticos; nadie puede registrarse con él
+const dominioBots = "@bots.invalid"
+
+var ErrCuentaBotOcupada = errors.New("La cuenta del jugador automático pertenece a otro jugador")
+
+// nombresBot son los jugadores automáticos de cada nivel, tal como aparecen en las partidas
+var nombresBot = map[string]string{
+	ia.NivelFacil:      "Bot (fácil)",
+	ia.NivelMedio:      "Bot (medio)",
+	ia.NivelDificil:    "Bot (difícil)",
+	ia.NivelMontecarlo: "Bot (Montecarlo)",
+}
+
+// CrearJuegoContraBot crea una partida con quien la pide sentado como en
+// CrearJuego y el jugador automático del nivel indicado en el siguiente asiento
+// libre. Si le toca empezar, se pone a pensar en segundo plano (ver responderBots)
+func (service *JuegoService) CrearJuegoContraBot(tipo string, creador *models.Jugador, jugadores []models.Jugador, nivel string) (engine.Game, error) {
+	reglas, err := service.Reglas(tipo)
+	if err != nil {
+		return nil, err
+	}
+	if err := ia.Disponible(tipo, nivel); err != nil {
+		return nil, err
+	}
+	bot, err := service.jugadorBot(nivel)
+	if err != nil {
+		return nil, err
+	}
+
+	sentados, err := sentarCreador(reglas, creador, jugadores)
+	if err != nil {
+		return nil, err
+	}
+	for len(sentados) < reglas.Seats() {
+		sentados = append(sentados, models.Jugador{})
+	}
+	libre := -1
+	for asiento, jugador := range sentados {
+		if models.AsientoLibre(jugador) {
+			libre = asiento
+			break
+		}
+	}
+	if libre < 0 {
+		return nil, ErrPartidaCompleta
+	}
+	sentados[libre] = *bot
+
+	juego, err := service.crear(tipo, sentados, nil)
+	if err != nil {
+		return nil, err
+	}
+	service.responderBots(tipo, juego.Comun().ID)
+	return juego, nil
+}
+
+// responderBots hace en segundo plano los movimientos de los jugadores
+// automáticos a los que les toque, para no retener la respuesta al jugador
+// mientras piensan. Sus movimientos llegan a los clientes por el hub, como los
+// de cualquier otro jugador. Cada partida tiene como mucho una búsqueda en
+// marcha: si se pide otra mientras tanto, la que hay vuelve a mirar al acabar
+func (service *JuegoService) responderBots(tipo, id string) {
+	service.botsMutex.Lock()
+	defer service.botsMutex.Unlock()
+	if _, pensando := service.pensando[id]; pensando {
+		service.pensando[id] = true
+		return
+	}
+	service.pensando[id] = false
+
+	go func() {
+		for {
+			if err := service.jugarBots(tipo, id); err != nil {
+				log.Printf("bots: %s %s: %v", tipo, id, err)
+			}
+			service.botsMutex.Lock()
+			if !service.pensando[id] {
+				delete(service.pensando, id)
+				service.botsMutex.Unlock()
+				return
+			}
+			service.pensando[id] = false
+			service.botsMutex.Unlock()
+		}
+	}()
+}
+
+// jugarBots mueve por los jugadores automáticos mientras les toque. La búsqueda
+// se hace sin el mutex para no frenar las demás partidas; si entretanto la
+// partida cambia, el movimiento calculado se descarta
+func (service *JuegoService) jugarBots(tipo, id string) error {
+	for {
+		juego, err := service.ObtenerJuego(tipo, id)
+		if err != nil {
+			return err
+		}
+		reglas, err := service.Reglas(tipo)
+		if err != nil {
+			return err
+		}
+		turno := reglas.CurrentPlayer(juego)
+		if juego.Comun().Estado != models.EstadoEnProgreso || turno == engine.AnySeat || !juego.Participantes()[turno].Bot {
+			return nil
+		}
+		nivel, err := service.nivelBot(juego.Participantes()[turno].ID)
+		if err != nil {
+			return err
+		}
+
+		hash, err := engine.HashState(juego)
+		if err != nil {
+			return err
+		}
+		movimiento, err := ia.Elegir(juego, nivel, service.Montecarlo)
+		if err != nil {
+			return err
+		}
+
+		err = service.actualizar(tipo, id, func(reglas engine.Rules, g engine.Game) (*models.Movimiento, error) {
+			if actual, err := engine.HashState(g); err != nil || actual != hash {
+				return nil, ErrPartidaCambiada
+			}
+			if _, err := jugar(reglas, g, turno, movimiento); err != nil {
+				return nil, err
+			}
+			return nuevoMovimiento(g, models.MovimientoJugada, turno, movimiento)
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// jugadorBot devuelve el jugador automático de un nivel y lo da de alta la
+// primera vez. No tiene contraseña, así que nadie puede iniciar sesión con él,
+// y su correo usa un dominio con el que no se puede registrar nadie
+func (service *JuegoService) jugadorBot(nivel string) (*models.Jugador, error) {
+	service.botsMutex.Lock()
+	defer service.botsMutex.Unlock()
+	if bot, existe := service.bots[nivel]; existe {
+		return &bot, nil
+	}
+	nombre, existe := nombresBot[nivel]
+	if !existe {
+		return nil, ia.ErrNivel
+	}
+
+	email := "bot-" + nivel + dominioBots
+	bot, err := service.Store.BuscarJugadorPorEmail(email)
+	if errors.Is(err, store.ErrNoEncontrado) {
+		bot = &models.Jugador{Name: nombre, Email: email, Bot: true}
+		err = service.Store.CrearJugador(bot)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !bot.Bot {
+		return nil, ErrCuentaBotOcupada
+	}
+	service.bots[nivel] = models.Jugador{ID: bot.ID, Name: bot.Name, Bot: true}
+	return &models.Jugador{ID: bot.ID, Name: bot.Name, Bot: true}, nil
+}
+
+// nivelBot devuelve el nivel del jugador automático con ese ID
+func (service *JuegoService) nivelBot(jugadorID uint) (string, error) {
+	for nivel := range nombresBot {
+		bot, err := service.jugadorBot(nivel)
+		if err != nil {
+			return "", err
+		}
+		if bot.ID == jugadorID {
+			return nivel, nil
+		}
+	}
+	return "", ia.ErrSinIA
+}
+
+// correoDeBot indica si el correo pertenece al dominio de los jugadores automáticos
+func correoDeBot(email string) bool {
+	return strings.HasSuffix(strings.ToLower(strings.TrimSpace(email)), dominioBots)
+}
+
+// conBots indica si en la partida juega algún jugador automático
+func conBots(juego engine.Game) bool {
+	for _, jugador := range juego.Participantes() {
+		if jugador.Bot {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"juego/engine"
+	"juego/ia"
+	"juego/models"
+	"testing"
+	"time"
+)
+
+func TestBotRespondeAlMovimiento(t *testing.T) {
+	for _, nivel := range []string{ia.NivelFacil, ia.NivelMontecarlo} {
+		t.Run(nivel, func(t *testing.T) {
+			service, jugadores := nuevoServicio(t, "ana")
+			juego, err := service.CrearJuegoContraBot(engine.TipoConectaCuatro, &jugadores[0], nil, nivel)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bot := juego.Participantes()[1]
+			if juego.Participantes()[0].ID != jugadores[0].ID || !bot.Bot {
+				t.Fatalf("asientos %+v: se esperaba ana y después el bot", juego.Participantes())
+			}
+
+			id := juego.Comun().ID
+			if _, _, err := service.HacerMovimiento(engine.TipoConectaCuatro, id, jugadores[0].ID, &engine.MovimientoConecta{Columna: 3}); err != nil {
+				t.Fatal(err)
+			}
+
+			// El bot piensa en segundo plano: su ficha aparece poco después
+			limite := time.Now().Add(5 * time.Second)
+			for {
+				juego, err := service.ObtenerJuego(engine.TipoConectaCuatro, id)
+				if err != nil {
+					t.Fatal(err)
+				}
+				conecta := juego.(*models.ConectaCuatro)
+				if conecta.Turno == 0 {
+					fichas := 0
+					for _, fila := range conecta.Tablero {
+						for _, celda := range fila {
+							if celda == engine.Ficha(1) {
+								fichas++
+							}
+						}
+					}
+					if fichas != 1 {
+						t.Fatalf("el bot ha dejado %d fichas", fichas)
+					}
+					return
+				}
+				if time.Now().After(limite) {
+					t.Fatal("el bot no ha respondido")
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		})
+	}
+}
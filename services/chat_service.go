@@ -0,0 +1,199 @@
+package services
+
+import (
+	"errors"
+	"juego/models"
+	"juego/realtime"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrCanalDesconocido = errors.New("Canal de chat desconocido")
+	ErrCanalNoPermitido = errors.New("No puedes usar este canal del chat")
+	ErrMensajeVacio     = errors.New("El mensaje está vacío")
+	ErrMensajeLargo     = errors.New("El mensaje es demasiado largo")
+	ErrSilencioNoValido = errors.New("Solo puedes silenciar a otro jugador de la partida")
+)
+
+// ChatService es el chat de cada partida. Los jugadores escriben en el canal de
+// jugadores, que también leen los espectadores; los espectadores tienen su propio
+// canal, que los jugadores no ven. Los mensajes se guardan con la partida
+type ChatService struct {
+	JuegoService   *JuegoService
+	Moderadores    []Moderador // Se aplican en orden antes de guardar cada mensaje
+	LongitudMaxima int         // Caracteres por mensaje
+}
+
+func NewChatService(juegoService *JuegoService, longitudMaxima int, moderadores ...Moderador) *ChatService {
+	return &ChatService{JuegoService: juegoService, LongitudMaxima: longitudMaxima, Moderadores: moderadores}
+}
+
+// Enviar guarda el mensaje del jugador y lo reparte a quien sigue la partida,
+// salvo a quien haya silenciado al autor. Sin canal se usa el que le corresponde:
+// el de jugadores si juega la partida y el de espectadores si no
+func (service *ChatService) Enviar(tipo, id string, jugador *models.Jugador, canal, texto string) (*models.MensajeChat, error) {
+	juego, err := service.JuegoService.ObtenerJuego(tipo, id)
+	if err != nil {
+		return nil, err
+	}
+
+	juega := participa(juego, jugador.ID)
+	if canal == "" {
+		canal = models.CanalEspectadores
+		if juega {
+			canal = models.CanalJugadores
+		}
+	}
+	if err := canalPermitido(canal, juega, true); err != nil {
+		return nil, err
+	}
+
+	texto = strings.TrimSpace(texto)
+	if texto == "" {
+		return nil, ErrMensajeVacio
+	}
+	if utf8.RuneCountInString(texto) > service.LongitudMaxima {
+		return nil, ErrMensajeLargo
+	}
+
+	mensaje := &models.MensajeChat{
+		PartidaID: id,
+		Canal:     canal,
+		JugadorID: jugador.ID,
+		Nombre:    jugador.Name,
+		Texto:     texto,
+		CreadoEn:  time.Now(),
+	}
+	for _, moderador := range service.Moderadores {
+		if err := moderador.Moderar(mensaje); err != nil {
+			return nil, err
+		}
+	}
+	if err := service.JuegoService.Store.CrearMensaje(mensaje); err != nil {
+		return nil, err
+	}
+
+	silenciado, err := service.silenciadoPor(id, jugador.ID)
+	if err != nil {
+		return nil, err
+	}
+	service.JuegoService.Hub.PublicarA(realtime.Evento{
+		Tipo:      realtime.EventoChat,
+		PartidaID: id,
+		Numero:    realtime.SinNumero,
+		Datos:     mensaje,
+	}, func(oyente realtime.Oyente) bool {
+		if canal == models.CanalEspectadores && !oyente.Espectador {
+			return false
+		}
+		return !silenciado[oyente.JugadorID]
+	})
+	return mensaje, nil
+}
+
+// Historial devuelve los últimos mensajes de un canal anteriores al ID antesDe
+// (0 para los más recientes), sin los de los jugadores que haya silenciado quien
+// consulta. jugador es nil para los espectadores anónimos
+func (service *ChatService) Historial(tipo, id string, jugador *models.Jugador, canal string, antesDe uint, limite int) ([]models.MensajeChat, error) {
+	juego, err := service.JuegoService.ObtenerJuego(tipo, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if canal == "" {
+		canal = models.CanalJugadores
+	}
+	if err := canalPermitido(canal, jugador != nil && participa(juego, jugador.ID), false); err != nil {
+		return nil, err
+	}
+
+	mensajes, err := service.JuegoService.Store.ListarMensajes(id, canal, antesDe, limite)
+	if err != nil || jugador == nil {
+		return mensajes, err
+	}
+
+	silencios, err := service.JuegoService.Store.ListarSilencios(id)
+	if err != nil {
+		return nil, err
+	}
+	silenciados := make(map[uint]bool)
+	for _, silencio := range silencios {
+		if silencio.JugadorID == jugador.ID {
+			silenciados[silencio.SilenciadoID] = true
+		}
+	}
+	visibles := mensajes[:0]
+	for _, mensaje := range mensajes {
+		if !silenciados[mensaje.JugadorID] {
+			visibles = append(visibles, mensaje)
+		}
+	}
+	return visibles, nil
+}
+
+// Silenciar oculta al jugador los mensajes de otro jugador de la misma partida
+func (service *ChatService) Silenciar(tipo, id string, jugadorID, silenciadoID uint) error {
+	if err := service.comprobarSilencio(tipo, id, jugadorID, silenciadoID); err != nil {
+		return err
+	}
+	return service.JuegoService.Store.Silenciar(models.Silencio{PartidaID: id, JugadorID: jugadorID, SilenciadoID: silenciadoID})
+}
+
+// QuitarSilencio vuelve a mostrar al jugador los mensajes del silenciado
+func (service *ChatService) QuitarSilencio(tipo, id string, jugadorID, silenciadoID uint) error {
+	if err := service.comprobarSilencio(tipo, id, jugadorID, silenciadoID); err != nil {
+		return err
+	}
+	return service.JuegoService.Store.QuitarSilencio(models.Silencio{PartidaID: id, JugadorID: jugadorID, SilenciadoID: silenciadoID})
+}
+
+// comprobarSilencio exige que los dos jueguen la partida y sean jugadores distintos
+func (service *ChatService) comprobarSilencio(tipo, id string, jugadorID, silenciadoID uint) error {
+	juego, err := service.JuegoService.ObtenerJuego(tipo, id)
+	if err != nil {
+		return err
+	}
+	if !participa(juego, jugadorID) {
+		return ErrNoParticipante
+	}
+	if silenciadoID == jugadorID || !participa(juego, silenciadoID) {
+		return ErrSilencioNoValido
+	}
+	return nil
+}
+
+// silenciadoPor devuelve los jugadores que han silenciado al autor en la partida
+func (service *ChatService) silenciadoPor(partidaID string, autorID uint) (map[uint]bool, error) {
+	silencios, err := service.JuegoService.Store.ListarSilencios(partidaID)
+	if err != nil {
+		return nil, err
+	}
+	silenciado := make(map[uint]bool)
+	for _, silencio := range silencios {
+		if silencio.SilenciadoID == autorID {
+			silenciado[silencio.JugadorID] = true
+		}
+	}
+	return silenciado, nil
+}
+
+// canalPermitido comprueba que quien juega (o no) la partida puede leer o
+// escribir en el canal: los espectadores leen el de jugadores pero no escriben
+// en él, y los jugadores no ven el de espectadores
+func canalPermitido(canal string, juega, escribir bool) error {
+	switch canal {
+	case models.CanalJugadores:
+		if escribir && !juega {
+			return ErrCanalNoPermitido
+		}
+	case models.CanalEspectadores:
+		if juega {
+			return ErrCanalNoPermitido
+		}
+	default:
+		return ErrCanalDesconocido
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"errors"
+	"juego/engine"
+	"juego/models"
+	"juego/store"
+	"time"
+)
+
+var (
+	ErrOrdenClasificacion   = errors.New("Orden de clasificación no válido")
+	ErrPeriodoClasificacion = errors.New("Periodo de clasificación no válido")
+)
+
+// Periodos de una clasificación. Mes y semana son los naturales en curso (en
+// UTC; la semana empieza el lunes), así que se vacían al empezar el siguiente
+const (
+	PeriodoSiempre = "siempre"
+	PeriodoMes     = "mes"
+	PeriodoSemana  = "semana"
+)
+
+// Clasificacion es una página de la clasificación de un tipo de juego
+type Clasificacion struct {
+	TipoJuego string                       `json:"tipo_juego"`
+	Orden     string                       `json:"orden"`
+	Periodo   string                       `json:"periodo"`
+	Desde     *time.Time                   `json:"desde,omitempty"` // Inicio del periodo
+	Total     int                          `json:"total"`           // Jugadores en la clasificación
+	Puestos   []models.PuestoClasificacion `json:"puestos"`
+	Jugador   *models.PuestoClasificacion  `json:"jugador"` // Puesto de quien consulta, si se ha identificado y aparece
+}
+
+// Clasificacion devuelve los mejores jugadores de un tipo de juego por puntuación
+// o por victorias y, si jugadorID no es 0, el puesto de ese jugador
+func (service *EstadisticaService) Clasificacion(tipo, orden, periodo string, limite, desplazamiento int, jugadorID uint) (*Clasificacion, error) {
+	if _, existe := engine.Lookup(tipo); !existe {
+		return nil, ErrTipoDesconocido
+	}
+	if orden == "" {
+		orden = models.OrdenPuntuacion
+	}
+	if orden != models.OrdenPuntuacion && orden != models.OrdenVictorias {
+		return nil, ErrOrdenClasificacion
+	}
+	if periodo == "" {
+		periodo = PeriodoSiempre
+	}
+	desde, err := inicioPeriodo(periodo, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	puestos, total, err := service.Store.ListarClasificacion(tipo, orden, desde, limite, desplazamiento)
+	if err != nil {
+		return nil, err
+	}
+	clasificacion := &Clasificacion{
+		TipoJuego: tipo,
+		Orden:     orden,
+		Periodo:   periodo,
+		Desde:     desde,
+		Total:     total,
+		Puestos:   append([]models.PuestoClasificacion{}, puestos...),
+	}
+
+	if jugadorID != 0 {
+		clasificacion.Jugador, err = service.Store.PosicionClasificacion(tipo, orden, desde, jugadorID)
+		if err != nil && !errors.Is(err, store.ErrNoEncontrado) {
+			return nil, err
+		}
+	}
+	return clasificacion, nil
+}
+
+// inicioPeriodo devuelve desde cuándo cuentan las partidas del periodo; nil para siempre
+func inicioPeriodo(periodo string, ahora time.Time) (*time.Time, error) {
+	ahora = ahora.UTC()
+	hoy := time.Date(ahora.Year(), ahora.Month(), ahora.Day(), 0, 0, 0, 0, time.UTC)
+	var desde time.Time
+	switch periodo {
+	case PeriodoSiempre:
+		return nil, nil
+	case PeriodoMes:
+		desde = hoy.AddDate(0, 0, 1-hoy.Day())
+	case PeriodoSemana:
+		desde = hoy.AddDate(0, 0, -((int(hoy.Weekday()) + 6) % 7))
+	default:
+		return nil, ErrPeriodoClasificacion
+	}
+	return &desde, nil
+}
@@ -0,0 +1,403 @@
+package services
+
+import (
+	"errors"
+	"juego/engine"
+	"juego/models"
+	"juego/realtime"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNoEnCola        = errors.New("No estás buscando partida")
+	ErrEnOtraCola      = errors.New("Ya estás buscando partida de otro tipo")
+	ErrTipoSinEmparejo = errors.New("Este juego no admite emparejamiento")
+)
+
+// Estados de un jugador en el emparejamiento
+const (
+	EmparejamientoEsperando  = "esperando"
+	EmparejamientoEmparejado = "emparejado"
+	EmparejamientoFuera      = "fuera"   // No está en ninguna cola
+	EmparejamientoFallido    = "fallido" // No se pudo crear su partida y ha salido de la cola
+)
+
+// Reintentos cuando falla la creación de la partida de un grupo: cada jugador
+// espera un poco más tras cada fallo y sale de la cola tras intentosPartida
+const (
+	intentosPartida = 3
+	esperaReintento = 5 * time.Second
+)
+
+// FuncionPuntuacion devuelve la puntuación de un jugador en un tipo de juego.
+// Con puntuaciones se emparejan jugadores de nivel parecido
+type FuncionPuntuacion func(jugadorID uint, tipo string) (int, error)
+
+// EstadoEmparejamiento es lo que sabe un jugador de su búsqueda de partida
+type EstadoEmparejamiento struct {
+	Estado    string     `json:"estado"`
+	TipoJuego string     `json:"tipo_juego,omitempty"`
+	PartidaID string     `json:"partida_id,omitempty"` // Partida creada al emparejarlo
+	Desde     *time.Time `json:"desde,omitempty"`      // Desde cuándo espera
+	EnCola    int        `json:"en_cola,omitempty"`    // Jugadores esperando en la misma cola
+	Rango     int        `json:"rango,omitempty"`      // Diferencia de puntuación que admite ahora mismo
+}
+
+// enCola es un jugador esperando partida
+type enCola struct {
+	jugador    models.Jugador
+	puntuacion int
+	desde      time.Time
+	intentos   int       // Partidas que no se le han podido crear
+	reintento  time.Time // Tras un fallo, no se le vuelve a emparejar hasta entonces
+}
+
+// grupo son los jugadores sacados de una cola para crearles una partida
+type grupo struct {
+	reglas    engine.Rules
+	jugadores []enCola // Por orden de llegada, que es el de los asientos
+}
+
+// EmparejamientoService junta a los jugadores que buscan partida del mismo tipo
+// y les crea la partida en cuanto hay tantos como asientos. Las colas viven en
+// memoria: al reiniciar el servidor los jugadores tienen que volver a entrar
+type EmparejamientoService struct {
+	JuegoService *JuegoService
+	Hub          *realtime.Hub     // Avisa a cada jugador de la partida que se le ha creado
+	Puntuacion   FuncionPuntuacion // Sin función se empareja por orden de llegada
+
+	RangoInicial        int
+	AmpliacionRango     int
+	IntervaloAmpliacion time.Duration
+
+	mutex       sync.Mutex
+	colas       map[string][]enCola
+	emparejados map[uint]EstadoEmparejamiento // Última partida creada a cada jugador
+	formando    map[uint]string               // Jugadores cuya partida se está creando y su tipo
+	detener     chan struct{}
+}
+
+func NewEmparejamientoService(juegoService *JuegoService, hub *realtime.Hub, rangoInicial, ampliacionRango int, intervaloAmpliacion time.Duration) *EmparejamientoService {
+	return &EmparejamientoService{
+		JuegoService:        juegoService,
+		Hub:                 hub,
+		RangoInicial:        rangoInicial,
+		AmpliacionRango:     ampliacionRango,
+		IntervaloAmpliacion: intervaloAmpliacion,
+		colas:               make(map[string][]enCola),
+		emparejados:         make(map[uint]EstadoEmparejamiento),
+		formando:            make(map[uint]string),
+	}
+}
+
+// Iniciar revisa las colas cada intervalo de ampliación, para emparejar a quien
+// ya entra en el rango de otro jugador por haber esperado más
+func (service *EmparejamientoService) Iniciar() {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+	if service.detener != nil {
+		return
+	}
+	service.detener = make(chan struct{})
+
+	go func(detener <-chan struct{}) {
+		ticker := time.NewTicker(service.IntervaloAmpliacion)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				var grupos []grupo
+				service.mutex.Lock()
+				for tipo := range service.colas {
+					grupos = append(grupos, service.emparejar(tipo)...)
+				}
+				service.mutex.Unlock()
+				service.crearPartidas(grupos)
+			case <-detener:
+				return
+			}
+		}
+	}(service.detener)
+}
+
+// Detener para la revisión periódica de las colas
+func (service *EmparejamientoService) Detener() {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+	if service.detener != nil {
+		close(service.detener)
+		service.detener = nil
+	}
+}
+
+// Buscar mete al jugador en la cola del tipo de juego y lo empareja si ya hay
+// rivales. Volver a buscar en la misma cola no le hace perder su sitio
+func (service *EmparejamientoService) Buscar(tipo string, jugador *models.Jugador) (EstadoEmparejamiento, error) {
+	reglas, err := service.JuegoService.Reglas(tipo)
+	if err != nil {
+		return EstadoEmparejamiento{}, err
+	}
+	if reglas.Seats() < 2 {
+		return EstadoEmparejamiento{}, ErrTipoSinEmparejo
+	}
+
+	puntuacion := 0
+	if service.Puntuacion != nil {
+		if puntuacion, err = service.Puntuacion(jugador.ID, tipo); err != nil {
+			return EstadoEmparejamiento{}, err
+		}
+	}
+
+	grupos, err := service.encolar(tipo, enCola{
+		jugador:    models.Jugador{ID: jugador.ID, Name: jugador.Name},
+		puntuacion: puntuacion,
+		desde:      time.Now(),
+	})
+	if err != nil {
+		return EstadoEmparejamiento{}, err
+	}
+	service.crearPartidas(grupos)
+	return service.Estado(jugador.ID), nil
+}
+
+// encolar mete al jugador en la cola si no estaba ya y devuelve los grupos que se
+// pueden formar con él
+func (service *EmparejamientoService) encolar(tipo string, esperando enCola) ([]grupo, error) {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	actual, _ := service.buscarEnColas(esperando.jugador.ID)
+	if actual == "" {
+		actual = service.formando[esperando.jugador.ID]
+	}
+	if actual != "" {
+		if actual != tipo {
+			return nil, ErrEnOtraCola
+		}
+		return nil, nil
+	}
+
+	delete(service.emparejados, esperando.jugador.ID)
+	service.colas[tipo] = append(service.colas[tipo], esperando)
+	return service.emparejar(tipo), nil
+}
+
+// Estado indica si el jugador sigue esperando o qué partida se le ha creado
+func (service *EmparejamientoService) Estado(jugadorID uint) EstadoEmparejamiento {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+	return service.estado(jugadorID)
+}
+
+// Cancelar saca al jugador de la cola en la que esté
+func (service *EmparejamientoService) Cancelar(jugadorID uint) error {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	tipo, posicion := service.buscarEnColas(jugadorID)
+	if tipo == "" {
+		if _, existe := service.formando[jugadorID]; existe {
+			// Si su partida llega a crearse se le sienta igual; si falla, no vuelve a la cola
+			delete(service.formando, jugadorID)
+			return nil
+		}
+		return ErrNoEnCola
+	}
+	cola := service.colas[tipo]
+	service.colas[tipo] = append(cola[:posicion:posicion], cola[posicion+1:]...)
+	return nil
+}
+
+// emparejar saca de la cola de un tipo todos los grupos posibles; las partidas
+// se crean después, sin el mutex (ver crearPartidas). Se empieza por quien más
+// tiempo lleva esperando y se le añaden, por orden de llegada, los jugadores
+// compatibles con todos los ya elegidos; requiere el mutex
+func (service *EmparejamientoService) emparejar(tipo string) []grupo {
+	reglas, err := service.JuegoService.Reglas(tipo)
+	if err != nil {
+		return nil
+	}
+	ahora := time.Now()
+
+	var grupos []grupo
+	for ancla := 0; ancla < len(service.colas[tipo]); ancla++ {
+		cola := service.colas[tipo]
+		if ahora.Before(cola[ancla].reintento) {
+			continue
+		}
+		elegidos := []int{ancla}
+		for candidato := range cola {
+			if len(elegidos) == reglas.Seats() {
+				break
+			}
+			if candidato != ancla && !ahora.Before(cola[candidato].reintento) && service.compatibleConGrupo(cola, elegidos, candidato, ahora) {
+				elegidos = append(elegidos, candidato)
+			}
+		}
+		if len(elegidos) < reglas.Seats() {
+			continue
+		}
+
+		sort.Ints(elegidos)
+		nuevo := grupo{reglas: reglas}
+		for _, posicion := range elegidos {
+			nuevo.jugadores = append(nuevo.jugadores, cola[posicion])
+			service.formando[cola[posicion].jugador.ID] = tipo
+		}
+		grupos = append(grupos, nuevo)
+		service.colas[tipo] = quitarDeCola(cola, elegidos)
+		ancla = -1 // La cola ha cambiado: se vuelve a empezar por el que más espera
+	}
+	return grupos
+}
+
+// crearPartidas crea la partida de cada grupo y se lo notifica a sus jugadores.
+// Si una falla, su grupo vuelve a la cola y se sigue con los demás; no requiere el mutex
+func (service *EmparejamientoService) crearPartidas(grupos []grupo) {
+	for _, g := range grupos {
+		jugadores := make([]models.Jugador, len(g.jugadores))
+		for i, esperando := range g.jugadores {
+			jugadores[i] = esperando.jugador
+		}
+		juego, err := service.JuegoService.crear(g.reglas.Type(), jugadores, nil)
+
+		service.mutex.Lock()
+		if err != nil {
+			log.Printf("emparejamiento: no se pudo crear la partida de %s: %v", g.reglas.Type(), err)
+			service.devolverACola(g)
+		} else {
+			service.avisarEmparejados(g, juego)
+		}
+		service.mutex.Unlock()
+	}
+}
+
+// avisarEmparejados apunta la partida creada a cada jugador del grupo y se lo notifica; requiere el mutex
+func (service *EmparejamientoService) avisarEmparejados(g grupo, juego engine.Game) {
+	partidaID := juego.Comun().ID
+	for _, esperando := range g.jugadores {
+		delete(service.formando, esperando.jugador.ID)
+		estado := EstadoEmparejamiento{Estado: EmparejamientoEmparejado, TipoJuego: g.reglas.Type(), PartidaID: partidaID}
+		service.emparejados[esperando.jugador.ID] = estado
+		service.avisar(esperando.jugador.ID, realtime.EventoEmparejado, partidaID, map[string]interface{}{"emparejamiento": estado, "juego": juego})
+	}
+}
+
+// devolverACola vuelve a poner en su sitio de la cola a los jugadores de un grupo
+// cuya partida no se pudo crear, salvo a los que han cancelado mientras tanto. Se
+// les deja esperar un poco antes de reintentar y, tras intentosPartida fallos,
+// salen de la cola con un aviso; requiere el mutex
+func (service *EmparejamientoService) devolverACola(g grupo) {
+	tipo := g.reglas.Type()
+	ahora := time.Now()
+	cola := service.colas[tipo]
+	for _, esperando := range g.jugadores {
+		if _, sigue := service.formando[esperando.jugador.ID]; !sigue {
+			continue
+		}
+		delete(service.formando, esperando.jugador.ID)
+
+		esperando.intentos++
+		if esperando.intentos >= intentosPartida {
+			estado := EstadoEmparejamiento{Estado: EmparejamientoFallido, TipoJuego: tipo}
+			service.emparejados[esperando.jugador.ID] = estado
+			service.avisar(esperando.jugador.ID, realtime.EventoSinPartida, "", map[string]interface{}{"emparejamiento": estado})
+			continue
+		}
+		esperando.reintento = ahora.Add(time.Duration(esperando.intentos) * esperaReintento)
+		cola = append(cola, esperando)
+	}
+	sort.SliceStable(cola, func(i, j int) bool { return cola[i].desde.Before(cola[j].desde) })
+	service.colas[tipo] = cola
+}
+
+// avisar envía un evento del emparejamiento a un jugador, si hay a quién avisar
+func (service *EmparejamientoService) avisar(jugadorID uint, tipo, partidaID string, datos map[string]interface{}) {
+	if service.Hub == nil {
+		return
+	}
+	service.Hub.Avisar(jugadorID, realtime.Evento{Tipo: tipo, PartidaID: partidaID, Numero: realtime.SinNumero, Datos: datos})
+}
+
+// compatibleConGrupo comprueba que el candidato entra en el rango de todos los
+// del grupo y todos ellos en el suyo
+func (service *EmparejamientoService) compatibleConGrupo(cola []enCola, grupo []int, candidato int, ahora time.Time) bool {
+	if service.Puntuacion == nil {
+		return true
+	}
+	for _, elegido := range grupo {
+		diferencia := cola[elegido].puntuacion - cola[candidato].puntuacion
+		if diferencia < 0 {
+			diferencia = -diferencia
+		}
+		if diferencia > service.rango(cola[elegido], ahora) || diferencia > service.rango(cola[candidato], ahora) {
+			return false
+		}
+	}
+	return true
+}
+
+// rango es la diferencia de puntuación que acepta un jugador según lo que lleva esperando
+func (service *EmparejamientoService) rango(jugador enCola, ahora time.Time) int {
+	if service.IntervaloAmpliacion <= 0 {
+		return service.RangoInicial
+	}
+	ampliaciones := int(ahora.Sub(jugador.desde) / service.IntervaloAmpliacion)
+	return service.RangoInicial + ampliaciones*service.AmpliacionRango
+}
+
+// estado construye el estado de un jugador; requiere el mutex
+func (service *EmparejamientoService) estado(jugadorID uint) EstadoEmparejamiento {
+	tipo, posicion := service.buscarEnColas(jugadorID)
+	if tipo == "" {
+		if tipo, existe := service.formando[jugadorID]; existe {
+			return EstadoEmparejamiento{Estado: EmparejamientoEsperando, TipoJuego: tipo}
+		}
+		if emparejado, existe := service.emparejados[jugadorID]; existe {
+			return emparejado
+		}
+		return EstadoEmparejamiento{Estado: EmparejamientoFuera}
+	}
+
+	esperando := service.colas[tipo][posicion]
+	estado := EstadoEmparejamiento{
+		Estado:    EmparejamientoEsperando,
+		TipoJuego: tipo,
+		Desde:     &esperando.desde,
+		EnCola:    len(service.colas[tipo]),
+	}
+	if service.Puntuacion != nil {
+		estado.Rango = service.rango(esperando, time.Now())
+	}
+	return estado
+}
+
+// buscarEnColas devuelve la cola y la posición del jugador, o "" si no espera; requiere el mutex
+func (service *EmparejamientoService) buscarEnColas(jugadorID uint) (string, int) {
+	for tipo, cola := range service.colas {
+		for posicion, esperando := range cola {
+			if esperando.jugador.ID == jugadorID {
+				return tipo, posicion
+			}
+		}
+	}
+	return "", 0
+}
+
+// quitarDeCola devuelve la cola sin las posiciones indicadas
+func quitarDeCola(cola []enCola, posiciones []int) []enCola {
+	quitar := make(map[int]bool, len(posiciones))
+	for _, posicion := range posiciones {
+		quitar[posicion] = true
+	}
+	resto := make([]enCola, 0, len(cola)-len(posiciones))
+	for posicion, esperando := range cola {
+		if !quitar[posicion] {
+			resto = append(resto, esperando)
+		}
+	}
+	return resto
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"juego/engine"
+	"juego/models"
+	"juego/realtime"
+	"testing"
+	"time"
+)
+
+// fantasma busca partida sin estar registrado: su partida nunca se puede crear
+var fantasma = models.Jugador{ID: 999, Name: "fantasma"}
+
+func TestFalloNoBloqueaLaCola(t *testing.T) {
+	juegoService, jugadores := nuevoServicio(t, "ana", "bea", "carla")
+	service := NewEmparejamientoService(juegoService, realtime.NewHub(), 0, 0, time.Minute)
+
+	// El primer grupo falla y vuelve a la cola, pero no se le reintenta enseguida
+	for _, jugador := range []models.Jugador{fantasma, jugadores[0]} {
+		jugador := jugador
+		if _, err := service.Buscar(engine.TipoConectaCuatro, &jugador); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if estado := service.Estado(jugadores[0].ID); estado.Estado != EmparejamientoEsperando {
+		t.Fatalf("ana: %+v, debería seguir esperando", estado)
+	}
+
+	// Los que llegan después se emparejan entre ellos
+	for _, jugador := range jugadores[1:] {
+		if _, err := service.Buscar(engine.TipoConectaCuatro, &jugador); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, jugador := range jugadores[1:] {
+		if estado := service.Estado(jugador.ID); estado.Estado != EmparejamientoEmparejado || estado.PartidaID == "" {
+			t.Fatalf("%s: %+v, se esperaba una partida", jugador.Name, estado)
+		}
+	}
+	if estado := service.Estado(jugadores[0].ID); estado.Estado != EmparejamientoEsperando || estado.EnCola != 2 {
+		t.Fatalf("ana: %+v, debería seguir en la cola con el fantasma", estado)
+	}
+}
+
+func TestSaleDeLaColaTrasVariosFallos(t *testing.T) {
+	juegoService, jugadores := nuevoServicio(t, "ana")
+	hub := realtime.NewHub()
+	service := NewEmparejamientoService(juegoService, hub, 0, 0, time.Minute)
+	suscripcion := hub.SuscribirJugador(jugadores[0].ID)
+	defer suscripcion.Cancelar()
+
+	for _, jugador := range []models.Jugador{fantasma, jugadores[0]} {
+		jugador := jugador
+		if _, err := service.Buscar(engine.TipoConectaCuatro, &jugador); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Los demás intentos, sin esperar a que pase el tiempo de reintento
+	for intento := 1; intento < intentosPartida; intento++ {
+		service.mutex.Lock()
+		cola := service.colas[engine.TipoConectaCuatro]
+		if len(cola) != 2 || cola[0].intentos != intento {
+			service.mutex.Unlock()
+			t.Fatalf("intento %d: cola %+v", intento, cola)
+		}
+		for i := range cola {
+			cola[i].reintento = time.Time{}
+		}
+		grupos := service.emparejar(engine.TipoConectaCuatro)
+		service.mutex.Unlock()
+		service.crearPartidas(grupos)
+	}
+
+	if estado := service.Estado(jugadores[0].ID); estado.Estado != EmparejamientoFallido {
+		t.Fatalf("ana: %+v, debería haber salido de la cola", estado)
+	}
+	select {
+	case evento := <-suscripcion.Eventos:
+		if evento.Tipo != realtime.EventoSinPartida {
+			t.Fatalf("evento %s, se esperaba %s", evento.Tipo, realtime.EventoSinPartida)
+		}
+	default:
+		t.Fatal("no se ha avisado a ana")
+	}
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"errors"
+	"juego/engine"
+	"juego/models"
+	"juego/store"
+)
+
+var ErrJugadorNoEncontrado = errors.New("Jugador no encontrado")
+
+// EstadisticaService consulta los resultados y las puntuaciones de cada jugador
+// por tipo de juego. Se actualizan solos al terminar cada partida (ver JuegoService.guardar)
+type EstadisticaService struct {
+	Store store.GameStore
+}
+
+func NewEstadisticaService(gameStore store.GameStore) *EstadisticaService {
+	return &EstadisticaService{Store: gameStore}
+}
+
+// Estadisticas devuelve el jugador y sus estadísticas en cada tipo de juego que
+// ha terminado alguna vez; con tipo solo las de ese juego
+func (service *EstadisticaService) Estadisticas(jugadorID uint, tipo string) (*models.Jugador, []models.Estadistica, error) {
+	jugador, err := service.buscarJugador(jugadorID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	todas, err := service.Store.ListarEstadisticas(jugadorID)
+	if err != nil {
+		return nil, nil, err
+	}
+	estadisticas := make([]models.Estadistica, 0, len(todas))
+	for _, estadistica := range todas {
+		if tipo != "" && estadistica.TipoJuego != tipo {
+			continue
+		}
+		if estadistica.Jugadas > 0 {
+			estadistica.DuracionMedia = float64(estadistica.DuracionTotal) / float64(estadistica.Jugadas)
+		}
+		estadistica.Provisional = provisional(estadistica)
+		estadisticas = append(estadisticas, estadistica)
+	}
+	return jugador, estadisticas, nil
+}
+
+// Perfil devuelve los datos públicos de un jugador con sus puntuaciones
+func (service *EstadisticaService) Perfil(jugadorID uint) (*models.Jugador, []PuntuacionJugador, error) {
+	jugador, err := service.buscarJugador(jugadorID)
+	if err != nil {
+		return nil, nil, err
+	}
+	puntuaciones, err := service.Puntuaciones(jugadorID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return jugador, puntuaciones, nil
+}
+
+// PuntuacionJugador es la puntuación de un jugador en un tipo de juego, tal como
+// se devuelve junto a los datos del jugador
+type PuntuacionJugador struct {
+	TipoJuego   string `json:"tipo_juego"`
+	Puntuacion  int    `json:"puntuacion"`
+	Provisional bool   `json:"provisional"`
+	Jugadas     int    `json:"jugadas"`
+}
+
+// Puntuaciones devuelve la puntuación del jugador en cada tipo de juego que ha
+// terminado alguna vez; en los demás tiene la inicial, provisional
+func (service *EstadisticaService) Puntuaciones(jugadorID uint) ([]PuntuacionJugador, error) {
+	estadisticas, err := service.Store.ListarEstadisticas(jugadorID)
+	if err != nil {
+		return nil, err
+	}
+	puntuaciones := make([]PuntuacionJugador, 0, len(estadisticas))
+	for _, estadistica := range estadisticas {
+		puntuaciones = append(puntuaciones, PuntuacionJugador{
+			TipoJuego:   estadistica.TipoJuego,
+			Puntuacion:  estadistica.Puntuacion,
+			Provisional: provisional(estadistica),
+			Jugadas:     estadistica.Jugadas,
+		})
+	}
+	return puntuaciones, nil
+}
+
+// Puntuacion devuelve la puntuación de un jugador en un tipo de juego; sirve de
+// FuncionPuntuacion para el emparejamiento
+func (service *EstadisticaService) Puntuacion(jugadorID uint, tipo string) (int, error) {
+	estadisticas, err := service.Store.ListarEstadisticas(jugadorID)
+	if err != nil {
+		return 0, err
+	}
+	for _, estadistica := range estadisticas {
+		if estadistica.TipoJuego == tipo {
+			return estadistica.Puntuacion, nil
+		}
+	}
+	return models.PuntuacionInicial, nil
+}
+
+// HistorialPuntuacion devuelve los últimos cambios de puntuación del jugador en un tipo de juego
+func (service *EstadisticaService) HistorialPuntuacion(jugadorID uint, tipo string, limite int) ([]models.HistorialPuntuacion, error) {
+	if _, existe := engine.Lookup(tipo); !existe {
+		return nil, ErrTipoDesconocido
+	}
+	if _, err := service.buscarJugador(jugadorID); err != nil {
+		return nil, err
+	}
+	return service.Store.ListarHistorialPuntuacion(jugadorID, tipo, limite)
+}
+
+// buscarJugador devuelve los datos públicos de un jugador, sin correo ni contraseña
+func (service *EstadisticaService) buscarJugador(jugadorID uint) (*models.Jugador, error) {
+	jugador, err := service.Store.ObtenerJugador(jugadorID)
+	if errors.Is(err, store.ErrNoEncontrado) {
+		return nil, ErrJugadorNoEncontrado
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &models.Jugador{ID: jugador.ID, Name: jugador.Name}, nil
+}
+
+// sumarResultados apunta en las estadísticas de cada jugador el resultado de una
+// partida recién terminada. Como actualizarPuntuaciones, no cuenta las partidas
+// en las que un jugador ocupa más de un asiento
+func sumarResultados(partida *models.Partida, estadisticas map[uint]*models.Estadistica) error {
+	if jugadorRepetido(partida.Participantes) {
+		return nil
+	}
+	duracion := int64(0)
+	if partida.TerminadoEn != nil {
+		duracion = int64(partida.TerminadoEn.Sub(partida.CreadoEn).Seconds())
+	}
+
+	for _, participante := range partida.Participantes {
+		estadistica, existe := estadisticas[participante.JugadorID]
+		if !existe {
+			continue
+		}
+		estadistica.Jugadas++
+		estadistica.DuracionTotal += duracion
+		estadistica.UltimaPartida = partida.TerminadoEn
+
+		switch participante.Resultado {
+		case models.ResultadoVictoria:
+			estadistica.Victorias++
+			if estadistica.RachaActual < 0 {
+				estadistica.RachaActual = 0
+			}
+			estadistica.RachaActual++
+		case models.ResultadoDerrota:
+			estadistica.Derrotas++
+			if estadistica.RachaActual > 0 {
+				estadistica.RachaActual = 0
+			}
+			estadistica.RachaActual--
+		default:
+			// Un empate corta cualquier racha
+			estadistica.Empates++
+			estadistica.RachaActual = 0
+		}
+		if estadistica.RachaActual > estadistica.MejorRacha {
+			estadistica.MejorRacha = estadistica.RachaActual
+		}
+		if -estadistica.RachaActual > estadistica.PeorRacha {
+			estadistica.PeorRacha = -estadistica.RachaActual
+		}
+	}
+	return nil
+}
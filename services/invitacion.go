@@ -0,0 +1,112 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"juego/engine"
+	"juego/models"
+	"juego/store"
+	"math/big"
+	"time"
+)
+
+var (
+	ErrCodigoNoValido  = errors.New("Código de invitación no válido")
+	ErrPartidaCompleta = errors.New("La partida no tiene asientos libres")
+	ErrYaParticipas    = errors.New("Ya juegas en esta partida")
+)
+
+// alfabetoCodigo deja fuera los caracteres que se confunden al leerlos (0/O, 1/I/L)
+const alfabetoCodigo = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// longitudCodigo da unos 900 millones de códigos distintos
+const longitudCodigo = 6
+
+// Invitacion devuelve una partida que espera jugadores junto con sus asientos libres
+func (service *JuegoService) Invitacion(tipo, id string) (engine.Game, []int, error) {
+	juego, err := service.ObtenerJuego(tipo, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if juego.Comun().Estado != models.EstadoEsperando || juego.Comun().Codigo == "" {
+		return nil, nil, ErrPartidaCompleta
+	}
+	return juego, asientosLibres(juego), nil
+}
+
+// UnirseJuego sienta al jugador en el primer asiento libre de la partida del
+// código. Cuando se ocupa el último asiento la partida empieza
+func (service *JuegoService) UnirseJuego(codigo string, jugador *models.Jugador) (engine.Game, int, error) {
+	partida, err := service.Store.BuscarPartidaPorCodigo(codigo)
+	if errors.Is(err, store.ErrNoEncontrado) {
+		return nil, 0, ErrCodigoNoValido
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var juego engine.Game
+	asiento := 0
+	err = service.actualizar(partida.TipoJuego, partida.ID, func(reglas engine.Rules, g engine.Game) (*models.Movimiento, error) {
+		if g.Comun().Estado != models.EstadoEsperando {
+			return nil, ErrPartidaCompleta
+		}
+		if participa(g, jugador.ID) {
+			return nil, ErrYaParticipas
+		}
+
+		libres := asientosLibres(g)
+		asiento = libres[0]
+		g.Sentar(asiento, models.Jugador{ID: jugador.ID, Name: jugador.Name})
+		if len(libres) == 1 {
+			g.Comun().Estado = models.EstadoEnProgreso
+			if reloj := g.Comun().Reloj; reloj != nil {
+				reloj.Desde = time.Now() // Empieza a correr el tiempo del primer turno
+			}
+		}
+		juego = g
+		// Se guarda el estado entero para que la repetición incluya a quien se ha sentado
+		return nuevoMovimiento(g, models.MovimientoUnion, asiento, g)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// En las partidas contra un bot, puede que le toque empezar a él
+	service.responderBots(partida.TipoJuego, partida.ID)
+	return juego, asiento, nil
+}
+
+// asientosLibres devuelve los asientos de la partida que siguen sin jugador
+func asientosLibres(juego engine.Game) []int {
+	var libres []int
+	for asiento, jugador := range juego.Participantes() {
+		if models.AsientoLibre(jugador) {
+			libres = append(libres, asiento)
+		}
+	}
+	return libres
+}
+
+// nuevoCodigo genera un código de invitación que no use ninguna otra partida; requiere el mutex
+func (service *JuegoService) nuevoCodigo() (string, error) {
+	for intento := 0; intento < 10; intento++ {
+		codigo := make([]byte, longitudCodigo)
+		for i := range codigo {
+			n, err := rand.Int(rand.Reader, big.NewInt(int64(len(alfabetoCodigo))))
+			if err != nil {
+				return "", err
+			}
+			codigo[i] = alfabetoCodigo[n.Int64()]
+		}
+
+		_, err := service.Store.BuscarPartidaPorCodigo(string(codigo))
+		if errors.Is(err, store.ErrNoEncontrado) {
+			return string(codigo), nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", errors.New("No se pudo generar un código de invitación libre")
+}
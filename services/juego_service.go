@@ -0,0 +1,587 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"juego/engine"
+	"juego/mcts"
+	"juego/models"
+	"juego/realtime"
+	"juego/store"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrJuegoNoEncontrado = errors.New("Juego no encontrado")
+	ErrTipoDesconocido   = errors.New("Tipo de juego desconocido")
+	ErrNoReiniciable     = errors.New("Este juego no se puede reiniciar")
+	ErrNoParticipante    = errors.New("No juegas en esta partida")
+	ErrNoEsTuTurno       = errors.New("No es tu turno")
+	ErrPartidaCambiada   = errors.New("La partida ha cambiado mientras se calculaba el movimiento")
+	ErrJugadorRepetido   = errors.New("Un jugador no puede ocupar dos asientos")
+	ErrDemasiados        = errors.New("Hay más jugadores que asientos")
+)
+
+// JuegoService gestiona las partidas de cualquier tipo de juego registrado en engine
+// y las guarda en el almacenamiento tras cada cambio
+type JuegoService struct {
+	Store             store.GameStore
+	Hub               *realtime.Hub // Recibe los cambios de cada partida para los clientes conectados
+	TiempoInactividad time.Duration // Sin movimientos durante este tiempo la partida queda abandonada (0 nunca)
+	Montecarlo        mcts.Config   // Presupuesto de los bots y las pistas del nivel Montecarlo
+	mutex             sync.Mutex    // Serializa los movimientos: leer, aplicar y guardar
+
+	botsMutex sync.Mutex
+	bots      map[string]models.Jugador // Jugador automático de cada nivel, una vez dado de alta
+	pensando  map[string]bool           // Partidas con un bot buscando; true si debe volver a mirar al acabar
+}
+
+func NewJuegoService(gameStore store.GameStore, hub *realtime.Hub, tiempoInactividad time.Duration, montecarlo mcts.Config) *JuegoService {
+	return &JuegoService{Store: gameStore, Hub: hub, TiempoInactividad: tiempoInactividad, Montecarlo: montecarlo, bots: make(map[string]models.Jugador), pensando: make(map[string]bool)}
+}
+
+// Reglas devuelve las reglas de un tipo de juego
+func (service *JuegoService) Reglas(tipo string) (engine.Rules, error) {
+	reglas, existe := engine.Lookup(tipo)
+	if !existe {
+		return nil, ErrTipoDesconocido
+	}
+	return reglas, nil
+}
+
+// CrearJuego crea una partida con el tablero vacío para los jugadores indicados.
+// Quien la crea juega en ella: si no está en la lista ocupa el primer asiento
+// libre. Si faltan jugadores (o alguno no tiene ID) sus asientos quedan libres
+// y la partida espera a que alguien se una con su código de invitación
+func (service *JuegoService) CrearJuego(tipo string, creador *models.Jugador, jugadores []models.Jugador) (engine.Game, error) {
+	reglas, err := service.Reglas(tipo)
+	if err != nil {
+		return nil, err
+	}
+	sentados, err := sentarCreador(reglas, creador, jugadores)
+	if err != nil {
+		return nil, err
+	}
+	return service.crear(tipo, sentados, nil)
+}
+
+// crear da de alta una partida, con los ajustes de sala si se crea desde el lobby
+func (service *JuegoService) crear(tipo string, jugadores []models.Jugador, sala *models.Sala) (engine.Game, error) {
+	reglas, err := service.Reglas(tipo)
+	if err != nil {
+		return nil, err
+	}
+	if len(jugadores) > reglas.Seats() {
+		return nil, fmt.Errorf("%w: como mucho %d", ErrDemasiados, reglas.Seats())
+	}
+	jugadores, err = service.comprobarJugadores(jugadores)
+	if err != nil {
+		return nil, err
+	}
+	for len(jugadores) < reglas.Seats() {
+		jugadores = append(jugadores, models.Jugador{})
+	}
+
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	codigo, err := service.nuevoCodigo()
+	if err != nil {
+		return nil, err
+	}
+
+	juego := reglas.NewState(uuid.New().String(), jugadores)
+	juego.Comun().Codigo = codigo
+	juego.Comun().Sala = sala
+	if sala != nil && sala.TiempoInicial > 0 {
+		juego.Comun().Reloj = nuevoReloj(reglas.Seats(), sala)
+	}
+	if len(asientosLibres(juego)) > 0 {
+		juego.Comun().Estado = models.EstadoEsperando
+	}
+
+	partida, err := registroPartida(juego)
+	if err != nil {
+		return nil, err
+	}
+	if err := service.Store.CrearPartida(partida); err != nil {
+		return nil, err
+	}
+	return juego, nil
+}
+
+// sentarCreador devuelve los jugadores con quien crea la partida sentado: donde
+// se haya puesto él mismo o, si no, en el primer asiento libre
+func sentarCreador(reglas engine.Rules, creador *models.Jugador, jugadores []models.Jugador) ([]models.Jugador, error) {
+	sentados := append([]models.Jugador(nil), jugadores...)
+	for _, jugador := range sentados {
+		if jugador.ID == creador.ID {
+			return sentados, nil
+		}
+	}
+	for asiento, jugador := range sentados {
+		if models.AsientoLibre(jugador) {
+			sentados[asiento] = models.Jugador{ID: creador.ID, Name: creador.Name}
+			return sentados, nil
+		}
+	}
+	if len(sentados) < reglas.Seats() {
+		return append(sentados, models.Jugador{ID: creador.ID, Name: creador.Name}), nil
+	}
+	return nil, ErrPartidaCompleta
+}
+
+// comprobarJugadores cambia cada jugador por sus datos registrados, para que
+// nadie se siente con un nombre o una marca de bot inventados, y rechaza los
+// IDs que no existen o que se repiten. Los asientos libres se quedan como están
+func (service *JuegoService) comprobarJugadores(jugadores []models.Jugador) ([]models.Jugador, error) {
+	comprobados := make([]models.Jugador, len(jugadores))
+	sentados := make(map[uint]bool)
+	for asiento, jugador := range jugadores {
+		if models.AsientoLibre(jugador) {
+			continue
+		}
+		if sentados[jugador.ID] {
+			return nil, ErrJugadorRepetido
+		}
+		sentados[jugador.ID] = true
+
+		registrado, err := service.Store.ObtenerJugador(jugador.ID)
+		if errors.Is(err, store.ErrNoEncontrado) {
+			return nil, ErrJugadorNoEncontrado
+		}
+		if err != nil {
+			return nil, err
+		}
+		comprobados[asiento] = models.Jugador{ID: registrado.ID, Name: registrado.Name, Bot: registrado.Bot}
+	}
+	return comprobados, nil
+}
+
+// ObtenerJuego devuelve una partida de un tipo concreto por su ID
+func (service *JuegoService) ObtenerJuego(tipo, id string) (engine.Game, error) {
+	reglas, err := service.Reglas(tipo)
+	if err != nil {
+		return nil, err
+	}
+	juego, _, err := service.cargar(tipo, id)
+	if err != nil || !service.vencida(reglas, juego) {
+		return juego, err
+	}
+	// La partida lleva demasiado tiempo parada o se ha agotado un reloj: actualizar
+	// guarda cómo ha acabado antes de devolverla
+	err = service.actualizar(tipo, id, func(_ engine.Rules, g engine.Game) (*models.Movimiento, error) {
+		juego = g
+		return nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return juego, nil
+}
+
+// Seguir suscribe al cliente a los cambios de la partida y devuelve el estado actual
+// con el número de movimientos de su historial. Se hace con el mutex tomado para
+// que ningún cambio quede entre el estado devuelto y el primer evento recibido.
+// Quien no juega la partida (o no se ha identificado) la sigue como espectador
+func (service *JuegoService) Seguir(tipo, id string, jugador *models.Jugador) (*realtime.Suscripcion, engine.Game, int, error) {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	juego, partida, err := service.cargar(tipo, id)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	if jugador != nil && participa(juego, jugador.ID) {
+		return service.Hub.SuscribirParticipante(id, jugador.ID), juego, partida.Movimientos, nil
+	}
+	espectador := realtime.Espectador{}
+	if jugador != nil {
+		espectador = realtime.Espectador{JugadorID: jugador.ID, Nombre: jugador.Name}
+	}
+	return service.Hub.SuscribirEspectador(id, espectador), juego, partida.Movimientos, nil
+}
+
+// Espectadores devuelve quiénes están mirando la partida en este momento
+func (service *JuegoService) Espectadores(tipo, id string) (realtime.Espectadores, error) {
+	if _, _, err := service.cargar(tipo, id); err != nil {
+		return realtime.Espectadores{}, err
+	}
+	return service.Hub.Espectadores(id), nil
+}
+
+// MovimientosDesde devuelve las entradas del historial posteriores al número indicado
+func (service *JuegoService) MovimientosDesde(tipo, id string, numero int) ([]models.Movimiento, error) {
+	if _, _, err := service.cargar(tipo, id); err != nil {
+		return nil, err
+	}
+	movimientos, err := service.Store.ListarMovimientos(id)
+	if err != nil {
+		return nil, err
+	}
+	for i, movimiento := range movimientos {
+		if movimiento.Numero > numero {
+			return movimientos[i:], nil
+		}
+	}
+	return nil, nil
+}
+
+// HacerMovimiento aplica el movimiento del jugador indicado, que debe participar
+// en la partida y tener el turno. Si después le toca a un jugador automático,
+// responde en segundo plano
+func (service *JuegoService) HacerMovimiento(tipo, id string, jugadorID uint, movimiento engine.Move) (engine.Game, engine.Outcome, error) {
+	var juego engine.Game
+	var resultado engine.Outcome
+
+	err := service.actualizar(tipo, id, func(reglas engine.Rules, g engine.Game) (*models.Movimiento, error) {
+		asiento, err := asientoParaMover(reglas, g, jugadorID)
+		if err != nil {
+			return nil, err
+		}
+		resultado, err = jugar(reglas, g, asiento, movimiento)
+		if err != nil {
+			return nil, err
+		}
+		juego = g
+		return nuevoMovimiento(g, models.MovimientoJugada, asiento, movimiento)
+	})
+	if err != nil {
+		return nil, engine.Outcome{}, err
+	}
+
+	service.responderBots(tipo, id)
+	return juego, resultado, nil
+}
+
+// ReiniciarJuego vuelve a dejar la partida como recién creada, si el juego lo
+// permite. Solo puede hacerlo quien juega en ella
+func (service *JuegoService) ReiniciarJuego(tipo, id string, jugadorID uint) (engine.Game, error) {
+	var juego engine.Game
+
+	err := service.actualizar(tipo, id, func(reglas engine.Rules, g engine.Game) (*models.Movimiento, error) {
+		if !participa(g, jugadorID) {
+			return nil, ErrNoParticipante
+		}
+		reiniciable, ok := reglas.(engine.Restarter)
+		if !ok {
+			return nil, ErrNoReiniciable
+		}
+		if g.Comun().Estado == models.EstadoEsperando {
+			return nil, engine.ErrEsperandoJugadores
+		}
+		reiniciable.Restart(g)
+		juego = g
+		// El reparto nuevo es aleatorio, así que se guarda el estado entero para poder repetirlo
+		return nuevoMovimiento(g, models.MovimientoReinicio, engine.AnySeat, g)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return juego, nil
+}
+
+// TerminarJuego da por terminada una partida; si seguía en curso o esperando
+// jugadores queda abandonada. Solo puede hacerlo quien juega en ella. La partida
+// se conserva en el almacenamiento para poder consultarla
+func (service *JuegoService) TerminarJuego(tipo, id string, jugadorID uint) (engine.Game, error) {
+	var juego engine.Game
+
+	err := service.actualizar(tipo, id, func(reglas engine.Rules, g engine.Game) (*models.Movimiento, error) {
+		if !participa(g, jugadorID) {
+			return nil, ErrNoParticipante
+		}
+		if estado := g.Comun().Estado; estado == models.EstadoEnProgreso || estado == models.EstadoEsperando {
+			abandonar(g)
+		}
+		juego = g
+		return nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return juego, nil
+}
+
+// participa indica si el jugador está sentado en la partida
+func participa(juego engine.Game, jugadorID uint) bool {
+	for _, jugador := range juego.Participantes() {
+		if jugador.ID == jugadorID {
+			return true
+		}
+	}
+	return false
+}
+
+// asientoParaMover devuelve el asiento con el que mueve el jugador, comprobando
+// que está sentado en la partida y que le toca
+func asientoParaMover(reglas engine.Rules, juego engine.Game, jugadorID uint) (int, error) {
+	turno := reglas.CurrentPlayer(juego)
+	participa := false
+	for asiento, jugador := range juego.Participantes() {
+		if jugador.ID != jugadorID {
+			continue
+		}
+		if turno == engine.AnySeat || turno == asiento {
+			return asiento, nil
+		}
+		participa = true
+	}
+	if !participa {
+		return 0, ErrNoParticipante
+	}
+	return 0, ErrNoEsTuTurno
+}
+
+// cambioPartida modifica una partida cargada y devuelve la entrada que deja en el historial, si deja alguna
+type cambioPartida func(engine.Rules, engine.Game) (*models.Movimiento, error)
+
+// actualizar carga una partida, le aplica un cambio y la vuelve a guardar
+func (service *JuegoService) actualizar(tipo, id string, cambio cambioPartida) error {
+	reglas, err := service.Reglas(tipo)
+	if err != nil {
+		return err
+	}
+
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	juego, anterior, err := service.cargar(tipo, id)
+	if err != nil {
+		return err
+	}
+	estadoAntes, turnoAntes := juego.Comun().Estado, reglas.CurrentPlayer(juego)
+	if service.vencida(reglas, juego) {
+		// La derrota por tiempo o el abandono se guardan aunque el cambio pedido falle después
+		service.vencer(reglas, juego)
+		if err := service.guardar(juego, anterior, nil); err != nil {
+			return err
+		}
+		service.publicarCambios(reglas, juego, anterior.Movimientos, nil, estadoAntes, turnoAntes)
+		estadoAntes = juego.Comun().Estado
+	}
+	movimiento, err := cambio(reglas, juego)
+	if err != nil {
+		return err
+	}
+	if err := service.guardar(juego, anterior, movimiento); err != nil {
+		return err
+	}
+	service.publicarCambios(reglas, juego, anterior.Movimientos, movimiento, estadoAntes, turnoAntes)
+	service.vigilarReloj(reglas, juego)
+	return nil
+}
+
+// publicarCambios avisa a los clientes conectados de lo que ha cambiado en la partida:
+// el movimiento o reinicio, el cambio de turno y el final de la partida
+func (service *JuegoService) publicarCambios(reglas engine.Rules, juego engine.Game, numero int, movimiento *models.Movimiento, estadoAntes string, turnoAntes int) {
+	if service.Hub == nil {
+		return
+	}
+	comun := juego.Comun()
+	evento := func(tipo string, datos map[string]interface{}) {
+		service.Hub.Publicar(realtime.Evento{Tipo: tipo, PartidaID: comun.ID, Numero: numero, Datos: datos})
+	}
+
+	if movimiento != nil {
+		tipo := realtime.EventoMovimiento
+		switch movimiento.Tipo {
+		case models.MovimientoReinicio:
+			tipo = realtime.EventoReinicio
+		case models.MovimientoUnion:
+			tipo = realtime.EventoUnion
+		}
+		evento(tipo, map[string]interface{}{"movimiento": movimiento, "juego": juego})
+	}
+
+	if comun.Estado != estadoAntes && comun.Estado != models.EstadoEnProgreso && comun.Estado != models.EstadoEsperando {
+		evento(realtime.EventoFin, map[string]interface{}{"estado": comun.Estado, "ganador": comun.Ganador, "juego": juego})
+		return
+	}
+	if comun.Estado != models.EstadoEnProgreso {
+		return
+	}
+	// Al ocuparse el último asiento empieza la partida y se avisa del primer turno
+	empieza := estadoAntes == models.EstadoEsperando
+	if turno := reglas.CurrentPlayer(juego); (turno != turnoAntes || empieza) && turno != engine.AnySeat {
+		evento(realtime.EventoTurno, map[string]interface{}{"turno": turno, "jugador": juego.Participantes()[turno]})
+	}
+}
+
+// guardar escribe en el almacenamiento el estado actual de la partida y, si lo
+// hay, añade el movimiento al historial detrás de los que ya tenía
+func (service *JuegoService) guardar(juego engine.Game, anterior *models.Partida, movimiento *models.Movimiento) error {
+	partida, err := registroPartida(juego)
+	if err != nil {
+		return err
+	}
+	partida.Movimientos = anterior.Movimientos
+	var movimientos []models.Movimiento
+	if movimiento != nil {
+		partida.Movimientos++
+		movimiento.Numero = partida.Movimientos
+		movimientos = append(movimientos, *movimiento)
+	}
+
+	// Las estadísticas y puntuaciones de los jugadores se apuntan en la misma
+	// transacción que el final de la partida
+	// Las partidas contra jugadores automáticos son de práctica y no cuentan, y
+	// tampoco las de un jugador contra sí mismo (crear ya no las permite, pero
+	// puede haberlas guardadas de antes)
+	if terminada(partida.Estado) && !terminada(anterior.Estado) && !conBots(juego) && !jugadorRepetido(partida.Participantes) {
+		err = service.Store.TerminarPartida(partida, func(estadisticas map[uint]*models.Estadistica) error {
+			actualizarPuntuaciones(partida, estadisticas)
+			return sumarResultados(partida, estadisticas)
+		}, movimientos...)
+	} else {
+		err = service.Store.GuardarPartida(partida, movimientos...)
+	}
+	if err != nil {
+		return err
+	}
+	anterior.Movimientos, anterior.Estado = partida.Movimientos, partida.Estado
+	return nil
+}
+
+// jugadorRepetido indica si algún jugador ocupa más de un asiento de la partida
+func jugadorRepetido(participantes []models.Participante) bool {
+	sentados := make(map[uint]bool, len(participantes))
+	for _, participante := range participantes {
+		if participante.JugadorID == 0 {
+			continue
+		}
+		if sentados[participante.JugadorID] {
+			return true
+		}
+		sentados[participante.JugadorID] = true
+	}
+	return false
+}
+
+// terminada indica si la partida ha acabado con ganador o en empate; las
+// abandonadas no cuentan en las estadísticas
+func terminada(estado string) bool {
+	return estado == models.EstadoTerminado || estado == models.EstadoEmpate
+}
+
+// caducada indica si la partida sigue en curso pero lleva demasiado tiempo sin movimientos
+func (service *JuegoService) caducada(juego engine.Game) bool {
+	comun := juego.Comun()
+	return service.TiempoInactividad > 0 &&
+		comun.Estado == models.EstadoEnProgreso &&
+		time.Since(comun.Actualizado) > service.TiempoInactividad
+}
+
+// cargar lee una partida y su tablero comprobando que es del tipo pedido
+func (service *JuegoService) cargar(tipo, id string) (engine.Game, *models.Partida, error) {
+	reglas, err := service.Reglas(tipo)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	partida, err := service.Store.ObtenerPartida(id)
+	if errors.Is(err, store.ErrNoEncontrado) || (err == nil && partida.TipoJuego != tipo) {
+		return nil, nil, ErrJuegoNoEncontrado
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	juego, err := engine.Decode(reglas, []byte(partida.Tablero.Datos))
+	if err != nil {
+		return nil, nil, err
+	}
+	return juego, partida, nil
+}
+
+// abandonar da por terminada sin ganador una partida que seguía en curso
+func abandonar(juego engine.Game) {
+	comun := juego.Comun()
+	comun.Estado = models.EstadoAbandonado
+	comun.Actualizado = time.Now()
+}
+
+// registroPartida construye el registro persistente (partida, participantes y
+// tablero) a partir del estado del juego
+func registroPartida(juego engine.Game) (*models.Partida, error) {
+	datos, err := json.Marshal(juego)
+	if err != nil {
+		return nil, err
+	}
+
+	comun := juego.Comun()
+	partida := &models.Partida{
+		ID:          comun.ID,
+		TipoJuego:   comun.TipoJuego,
+		Estado:      comun.Estado,
+		CreadoEn:    comun.CreadoEn,
+		Actualizado: comun.Actualizado,
+		Tablero:     models.TableroPartida{PartidaID: comun.ID, Datos: string(datos), Inicial: string(datos)},
+	}
+	if comun.Codigo != "" {
+		partida.Codigo = &comun.Codigo
+	}
+	if comun.Sala != nil {
+		partida.Sala = *comun.Sala
+	}
+	if comun.Ganador != nil {
+		partida.GanadorID = &comun.Ganador.ID
+	}
+	if comun.Estado != models.EstadoEnProgreso && comun.Estado != models.EstadoEsperando {
+		partida.TerminadoEn = &comun.Actualizado
+	}
+
+	for asiento, jugador := range juego.Participantes() {
+		partida.Participantes = append(partida.Participantes, models.Participante{
+			PartidaID: comun.ID,
+			Asiento:   asiento,
+			JugadorID: jugador.ID,
+			Nombre:    jugador.Name,
+			Resultado: resultadoDe(comun, jugador),
+		})
+	}
+	return partida, nil
+}
+
+// nuevoMovimiento prepara la entrada del historial para el estado que ha dejado
+// un movimiento; el número se asigna al guardarla
+func nuevoMovimiento(juego engine.Game, tipo string, asiento int, datos interface{}) (*models.Movimiento, error) {
+	datosJSON, err := json.Marshal(datos)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := engine.HashState(juego)
+	if err != nil {
+		return nil, err
+	}
+
+	movimiento := &models.Movimiento{
+		PartidaID:  juego.Comun().ID,
+		Tipo:       tipo,
+		Asiento:    asiento,
+		Datos:      string(datosJSON),
+		HashEstado: hash,
+		CreadoEn:   juego.Comun().Actualizado,
+	}
+	if asiento >= 0 {
+		movimiento.JugadorID = juego.Participantes()[asiento].ID
+	}
+	return movimiento, nil
+}
+
+// resultadoDe calcula el resultado de un participante; vacío si la partida sigue en curso
+func resultadoDe(comun *models.Juego, jugador models.Jugador) string {
+	switch {
+	case comun.Estado == models.EstadoEmpate:
+		return models.ResultadoEmpate
+	case comun.Ganador != nil && comun.Ganador.ID == jugador.ID:
+		return models.ResultadoVictoria
+	case comun.Ganador != nil:
+		return models.ResultadoDerrota
+	}
+	return ""
+}
@@ -2,25 +2,30 @@ package services
 
 import (
     "errors"
-    "fmt"
     "juego/models"
+    "juego/store"
 
     "golang.org/x/crypto/bcrypt"
-    "gorm.io/gorm"
 )
 
 type JugadorService struct {
-    DB *gorm.DB
+    Store store.GameStore
 }
 
-func NewJugadorService(db *gorm.DB) *JugadorService {
-    return &JugadorService{DB: db}
+func NewJugadorService(gameStore store.GameStore) *JugadorService {
+    return &JugadorService{Store: gameStore}
 }
 
 func (service *JugadorService) RegisterJugador(jugador *models.Jugador) (*models.Jugador, error) {
-    var existing models.RegisterInput
-    if err := service.DB.Where("Email = ?", jugador.Email).First(&existing).Error; err == nil {
+    // El dominio de los jugadores automáticos está reservado: quien se registrara
+    // con él se quedaría con la cuenta del bot
+    if correoDeBot(jugador.Email) {
+        return nil, errors.New("ese dominio de correo está reservado")
+    }
+    if _, err := service.Store.BuscarJugadorPorEmail(jugador.Email); err == nil {
         return nil, errors.New("el correo ya está registrado")
+    } else if !errors.Is(err, store.ErrNoEncontrado) {
+        return nil, err
     }
 
     hashedPassword, err := bcrypt.GenerateFromPassword([]byte(jugador.Password), bcrypt.DefaultCost)
@@ -29,7 +34,7 @@ func (service *JugadorService) RegisterJugador(jugador *models.Jugador) (*models
     }
     jugador.Password = string(hashedPassword)
 
-    if err := service.DB.Create(jugador).Error; err != nil {
+    if err := service.Store.CrearJugador(jugador); err != nil {
         return nil, err
     }
 
@@ -41,19 +46,14 @@ func (service *JugadorService) RegisterJugador(jugador *models.Jugador) (*models
 }
 
 func (service *JugadorService) LoginJugador(email, password string) (*models.Jugador, error) {
-    var jugador models.Jugador
-    err := service.DB.Where("Email = ?", email).First(&jugador).Error
+    jugador, err := service.Store.BuscarJugadorPorEmail(email)
     if err != nil {
-        fmt.Printf("DEBUG DB ERROR: %v\n", err)
-        if errors.Is(err, gorm.ErrRecordNotFound) {
+        if errors.Is(err, store.ErrNoEncontrado) {
             return nil, errors.New("correo o contraseña incorrectos")
         }
         return nil, err
     }
 
-    fmt.Printf("DEBUG BD hash: %s\n", jugador.Password)
-    fmt.Printf("DEBUG Password recibido: %s\n", password)
-
     if err := bcrypt.CompareHashAndPassword([]byte(jugador.Password), []byte(password)); err != nil {
         return nil, errors.New("correo o contraseña incorrectos")
     }
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+	"juego/models"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+var ErrDemasiadosMensajes = errors.New("Estás enviando mensajes demasiado rápido")
+
+// Moderador revisa un mensaje del chat antes de guardarlo. Puede cambiar su
+// texto (censurarlo) o rechazarlo devolviendo un error
+type Moderador interface {
+	Moderar(mensaje *models.MensajeChat) error
+}
+
+// FiltroPalabras sustituye por asteriscos las palabras prohibidas, sin
+// distinguir mayúsculas de minúsculas
+type FiltroPalabras struct {
+	patron *regexp.Regexp // nil si no hay palabras prohibidas
+}
+
+func NewFiltroPalabras(palabras []string) *FiltroPalabras {
+	alternativas := make([]string, 0, len(palabras))
+	for _, palabra := range palabras {
+		if palabra = strings.TrimSpace(palabra); palabra != "" {
+			alternativas = append(alternativas, regexp.QuoteMeta(palabra))
+		}
+	}
+	if len(alternativas) == 0 {
+		return &FiltroPalabras{}
+	}
+	return &FiltroPalabras{patron: regexp.MustCompile("(?i)" + strings.Join(alternativas, "|"))}
+}
+
+func (filtro *FiltroPalabras) Moderar(mensaje *models.MensajeChat) error {
+	if filtro.patron == nil {
+		return nil
+	}
+	mensaje.Texto = filtro.patron.ReplaceAllStringFunc(mensaje.Texto, func(palabra string) string {
+		return strings.Repeat("*", utf8.RuneCountInString(palabra))
+	})
+	return nil
+}
+
+// LimiteMensajes rechaza los mensajes de un jugador que ya ha enviado Maximo
+// mensajes en la última Ventana, sumando los de todas sus partidas
+type LimiteMensajes struct {
+	Maximo  int
+	Ventana time.Duration
+
+	mutex   sync.Mutex
+	envios  map[uint][]time.Time // Envíos de cada jugador dentro de la ventana, del más antiguo al más reciente
+	limpiar time.Time            // Próxima vez que se borran los jugadores sin envíos recientes
+}
+
+func NewLimiteMensajes(maximo int, ventana time.Duration) *LimiteMensajes {
+	return &LimiteMensajes{Maximo: maximo, Ventana: ventana, envios: make(map[uint][]time.Time)}
+}
+
+func (limite *LimiteMensajes) Moderar(mensaje *models.MensajeChat) error {
+	limite.mutex.Lock()
+	defer limite.mutex.Unlock()
+
+	ahora := time.Now()
+	if ahora.After(limite.limpiar) {
+		for jugadorID, envios := range limite.envios {
+			if len(recientes(envios, ahora.Add(-limite.Ventana))) == 0 {
+				delete(limite.envios, jugadorID)
+			}
+		}
+		limite.limpiar = ahora.Add(limite.Ventana)
+	}
+
+	envios := recientes(limite.envios[mensaje.JugadorID], ahora.Add(-limite.Ventana))
+	if len(envios) >= limite.Maximo {
+		limite.envios[mensaje.JugadorID] = envios
+		return ErrDemasiadosMensajes
+	}
+	limite.envios[mensaje.JugadorID] = append(envios, ahora)
+	return nil
+}
+
+// recientes descarta los envíos anteriores al inicio de la ventana
+func recientes(envios []time.Time, desde time.Time) []time.Time {
+	for len(envios) > 0 && !envios[0].After(desde) {
+		envios = envios[1:]
+	}
+	return envios
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"juego/engine"
+	"juego/models"
+	"juego/store"
+	"time"
+)
+
+var ErrFechasNoValidas = errors.New("La fecha de inicio debe ser anterior a la de fin")
+
+// Resultado de las partidas abandonadas, que no tienen ganador ni empate
+const ResultadoAbandonada = "abandonada"
+
+// PartidaJugada es una partida terminada vista desde uno de sus jugadores
+type PartidaJugada struct {
+	ID          string                `json:"id"`
+	TipoJuego   string                `json:"tipo_juego"`
+	Estado      string                `json:"estado"`
+	Resultado   string                `json:"resultado"` // victoria, derrota, empate o abandonada
+	Asiento     int                   `json:"asiento"`
+	Rivales     []models.Participante `json:"rivales"`
+	CreadoEn    time.Time             `json:"creado_en"`
+	TerminadoEn time.Time             `json:"terminado_en"`
+	Duracion    int64                 `json:"duracion"` // Segundos
+	Movimientos int                   `json:"num_movimientos"`
+	Repeticion  string                `json:"repeticion"` // Ruta de la repetición de la partida
+}
+
+// HistorialPartidas es una página de las partidas terminadas de un jugador
+type HistorialPartidas struct {
+	Jugador  *models.Jugador `json:"jugador"`
+	Total    int             `json:"total"` // Partidas que cumplen el filtro
+	Partidas []PartidaJugada `json:"partidas"`
+}
+
+// PartidasJugador devuelve las partidas terminadas de un jugador, las más
+// recientes primero, con sus rivales y el resultado que sacó en cada una
+func (service *EstadisticaService) PartidasJugador(jugadorID uint, filtro store.FiltroPartidas) (*HistorialPartidas, error) {
+	if filtro.TipoJuego != "" {
+		if _, existe := engine.Lookup(filtro.TipoJuego); !existe {
+			return nil, ErrTipoDesconocido
+		}
+	}
+	if filtro.Desde != nil && filtro.Hasta != nil && !filtro.Desde.Before(*filtro.Hasta) {
+		return nil, ErrFechasNoValidas
+	}
+	jugador, err := service.buscarJugador(jugadorID)
+	if err != nil {
+		return nil, err
+	}
+
+	partidas, total, err := service.Store.ListarPartidasJugador(jugadorID, filtro)
+	if err != nil {
+		return nil, err
+	}
+	historial := &HistorialPartidas{Jugador: jugador, Total: total, Partidas: make([]PartidaJugada, 0, len(partidas))}
+	for _, partida := range partidas {
+		historial.Partidas = append(historial.Partidas, partidaJugada(partida, jugadorID))
+	}
+	return historial, nil
+}
+
+// partidaJugada resume una partida terminada desde el punto de vista del jugador
+func partidaJugada(partida models.Partida, jugadorID uint) PartidaJugada {
+	jugada := PartidaJugada{
+		ID:          partida.ID,
+		TipoJuego:   partida.TipoJuego,
+		Estado:      partida.Estado,
+		Resultado:   ResultadoAbandonada,
+		Asiento:     -1,
+		Rivales:     []models.Participante{},
+		CreadoEn:    partida.CreadoEn,
+		TerminadoEn: *partida.TerminadoEn,
+		Duracion:    int64(partida.TerminadoEn.Sub(partida.CreadoEn).Seconds()),
+		Movimientos: partida.Movimientos,
+		Repeticion:  fmt.Sprintf("/games/%s/%s/replay", partida.TipoJuego, partida.ID),
+	}
+	for _, participante := range partida.Participantes {
+		if participante.JugadorID == jugadorID && jugada.Asiento < 0 {
+			jugada.Asiento = participante.Asiento
+			if participante.Resultado != "" {
+				jugada.Resultado = participante.Resultado
+			}
+			continue
+		}
+		if participante.JugadorID != 0 { // Las salas cerradas antes de empezar tienen asientos libres
+			jugada.Rivales = append(jugada.Rivales, participante)
+		}
+	}
+	return jugada
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"errors"
+	"juego/engine"
+	"juego/ia"
+	"juego/models"
+)
+
+var ErrSinMovimientosLegales = errors.New("Este juego no puede listar sus movimientos legales")
+
+// MovimientosLegales devuelve el asiento que tiene el turno y los movimientos
+// que puede hacer, para que los clientes no tengan que repetir las reglas.
+// Si la partida no está en curso la lista va vacía
+func (service *JuegoService) MovimientosLegales(tipo, id string) (int, []engine.Move, error) {
+	reglas, err := service.Reglas(tipo)
+	if err != nil {
+		return 0, nil, err
+	}
+	generador, ok := reglas.(engine.MoveGenerator)
+	if !ok {
+		return 0, nil, ErrSinMovimientosLegales
+	}
+	juego, err := service.ObtenerJuego(tipo, id)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	turno := reglas.CurrentPlayer(juego)
+	if juego.Comun().Estado != models.EstadoEnProgreso {
+		return turno, []engine.Move{}, nil
+	}
+	movimientos := generador.LegalMoves(juego)
+	if movimientos == nil {
+		movimientos = []engine.Move{}
+	}
+	return turno, movimientos, nil
+}
+
+// Pista busca un movimiento para el jugador, que debe tener el turno, con la
+// fuerza del nivel indicado. Devuelve también la valoración de la posición
+// tras él desde su punto de vista (ver ia.Sugerir)
+func (service *JuegoService) Pista(tipo, id string, jugadorID uint, nivel string) (engine.Move, int, error) {
+	reglas, err := service.Reglas(tipo)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := ia.Disponible(tipo, nivel); err != nil {
+		return nil, 0, err
+	}
+	juego, err := service.ObtenerJuego(tipo, id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch juego.Comun().Estado {
+	case models.EstadoEnProgreso:
+	case models.EstadoEsperando:
+		return nil, 0, engine.ErrEsperandoJugadores
+	default:
+		return nil, 0, engine.ErrJuegoTerminado
+	}
+	if _, err := asientoParaMover(reglas, juego, jugadorID); err != nil {
+		return nil, 0, err
+	}
+	return ia.Sugerir(juego, nivel, service.Montecarlo)
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"juego/models"
+	"math"
+)
+
+// Parámetros del Elo. Los jugadores provisionales (menos de partidasProvisionales
+// terminadas en el tipo de juego) usan un factor K mayor para llegar antes a su nivel
+const (
+	partidasProvisionales = 20
+	factorK               = 20.0
+	factorKProvisional    = 40.0
+)
+
+// provisional indica si la puntuación aún no es fiable por haber jugado pocas partidas
+func provisional(estadistica models.Estadistica) bool {
+	return estadistica.Jugadas < partidasProvisionales
+}
+
+// actualizarPuntuaciones aplica el Elo a los jugadores de una partida recién
+// terminada. Con más de dos jugadores cada uno se compara con todos los demás
+// y el factor K se reparte entre los rivales. Debe llamarse antes de sumarResultados,
+// que es quien cuenta la partida como jugada. Las partidas en las que un jugador
+// ocupa más de un asiento no cambian ninguna puntuación, igual que no cuentan en
+// las estadísticas
+func actualizarPuntuaciones(partida *models.Partida, estadisticas map[uint]*models.Estadistica) {
+	if jugadorRepetido(partida.Participantes) {
+		return
+	}
+	participantes := make([]models.Participante, 0, len(partida.Participantes))
+	for _, participante := range partida.Participantes {
+		if _, existe := estadisticas[participante.JugadorID]; existe {
+			participantes = append(participantes, participante)
+		}
+	}
+	if len(participantes) < 2 {
+		return
+	}
+
+	cambios := make(map[uint]float64, len(participantes))
+	for i, jugador := range participantes {
+		estadistica := estadisticas[jugador.JugadorID]
+		k := factorK
+		if provisional(*estadistica) {
+			k = factorKProvisional
+		}
+		k /= float64(len(participantes) - 1)
+
+		for j, rival := range participantes {
+			if j == i {
+				continue
+			}
+			esperado := 1 / (1 + math.Pow(10, float64(estadisticas[rival.JugadorID].Puntuacion-estadistica.Puntuacion)/400))
+			cambios[jugador.JugadorID] += k * (puntosContra(jugador.Resultado, rival.Resultado) - esperado)
+		}
+	}
+
+	// Los cambios se calculan con las puntuaciones de antes de la partida y se aplican al final
+	for jugadorID, cambio := range cambios {
+		estadisticas[jugadorID].Puntuacion += int(math.Round(cambio))
+	}
+}
+
+// puntosContra es lo que saca un jugador frente a un rival: 1 si quedó por
+// delante, 0 si quedó por detrás y 0,5 si quedaron igual
+func puntosContra(resultado, rival string) float64 {
+	orden := map[string]int{models.ResultadoDerrota: 0, models.ResultadoEmpate: 1, models.ResultadoVictoria: 2}
+	switch {
+	case orden[resultado] > orden[rival]:
+		return 1
+	case orden[resultado] < orden[rival]:
+		return 0
+	}
+	return 0.5
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"juego/engine"
+	"juego/mcts"
+	"juego/models"
+	"juego/realtime"
+	"juego/store"
+	"testing"
+)
+
+// partidaTerminada prepara una partida de Conecta Cuatro con los resultados de
+// cada asiento; los jugadores tienen como ID su asiento más uno
+func partidaTerminada(resultados ...string) *models.Partida {
+	partida := &models.Partida{ID: "prueba", TipoJuego: engine.TipoConectaCuatro, Estado: models.EstadoTerminado}
+	for asiento, resultado := range resultados {
+		partida.Participantes = append(partida.Participantes, models.Participante{Asiento: asiento, JugadorID: uint(asiento + 1), Resultado: resultado})
+	}
+	return partida
+}
+
+// estadisticasDe prepara las estadísticas de los jugadores 1, 2... con la
+// puntuación y las partidas jugadas indicadas
+func estadisticasDe(puntuaciones, jugadas []int) map[uint]*models.Estadistica {
+	estadisticas := make(map[uint]*models.Estadistica, len(puntuaciones))
+	for i := range puntuaciones {
+		estadisticas[uint(i+1)] = &models.Estadistica{JugadorID: uint(i + 1), Puntuacion: puntuaciones[i], Jugadas: jugadas[i]}
+	}
+	return estadisticas
+}
+
+func TestActualizarPuntuaciones(t *testing.T) {
+	const (
+		V = models.ResultadoVictoria
+		D = models.ResultadoDerrota
+		E = models.ResultadoEmpate
+	)
+	casos := []struct {
+		nombre       string
+		resultados   []string
+		puntuaciones []int
+		jugadas      []int
+		despues      []int
+	}{
+		// Con la misma puntuación se espera medio punto: el cambio es K/2
+		{"iguales y provisionales", []string{V, D}, []int{1500, 1500}, []int{0, 0}, []int{1520, 1480}},
+		{"iguales y establecidos", []string{V, D}, []int{1500, 1500}, []int{30, 30}, []int{1510, 1490}},
+		{"empate entre iguales", []string{E, E}, []int{1500, 1500}, []int{30, 30}, []int{1500, 1500}},
+		// Con 200 puntos de ventaja se espera 1/(1+10^-0.5) ≈ 0,76
+		{"gana el favorito", []string{V, D}, []int{1700, 1500}, []int{30, 30}, []int{1705, 1495}},
+		{"gana el rival más flojo", []string{D, V}, []int{1700, 1500}, []int{30, 30}, []int{1685, 1515}},
+		{"el favorito empata", []string{E, E}, []int{1700, 1500}, []int{30, 30}, []int{1695, 1505}},
+		// Cada uno usa su propio factor K, así que los cambios no tienen por qué compensarse
+		{"provisional contra establecido", []string{V, D}, []int{1500, 1500}, []int{5, 30}, []int{1520, 1490}},
+		// Con tres jugadores K se reparte entre los dos rivales de cada uno
+		{"tres jugadores", []string{V, D, D}, []int{1500, 1500, 1500}, []int{30, 30, 30}, []int{1510, 1495, 1495}},
+		{"tres jugadores con dos empatados arriba", []string{V, V, D}, []int{1500, 1500, 1500}, []int{30, 30, 30}, []int{1505, 1505, 1490}},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			estadisticas := estadisticasDe(caso.puntuaciones, caso.jugadas)
+			actualizarPuntuaciones(partidaTerminada(caso.resultados...), estadisticas)
+			for i, esperada := range caso.despues {
+				if puntuacion := estadisticas[uint(i+1)].Puntuacion; puntuacion != esperada {
+					t.Fatalf("jugador %d: %d puntos, se esperaban %d", i+1, puntuacion, esperada)
+				}
+			}
+		})
+	}
+}
+
+func TestActualizarPuntuacionesSinCambios(t *testing.T) {
+	casos := []struct {
+		nombre       string
+		partida      *models.Partida
+		estadisticas map[uint]*models.Estadistica
+	}{
+		{
+			"jugador repetido",
+			&models.Partida{Participantes: []models.Participante{
+				{Asiento: 0, JugadorID: 1, Resultado: models.ResultadoVictoria},
+				{Asiento: 1, JugadorID: 1, Resultado: models.ResultadoDerrota},
+			}},
+			estadisticasDe([]int{1500}, []int{0}),
+		},
+		{
+			"un solo jugador con estadísticas",
+			partidaTerminada(models.ResultadoVictoria, models.ResultadoDerrota),
+			estadisticasDe([]int{1500}, []int{0}),
+		},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			actualizarPuntuaciones(caso.partida, caso.estadisticas)
+			if puntuacion := caso.estadisticas[1].Puntuacion; puntuacion != 1500 {
+				t.Fatalf("%d puntos, no debería haber cambiado", puntuacion)
+			}
+		})
+	}
+}
+
+func TestProvisionalPasaAEstablecido(t *testing.T) {
+	// La partida número partidasProvisionales aún se puntúa con el factor K provisional;
+	// a partir de ahí se usa el normal
+	estadisticas := estadisticasDe([]int{1500, 1500}, []int{partidasProvisionales - 1, partidasProvisionales - 1})
+	if !provisional(*estadisticas[1]) {
+		t.Fatalf("con %d partidas aún debería ser provisional", estadisticas[1].Jugadas)
+	}
+
+	partida := partidaTerminada(models.ResultadoVictoria, models.ResultadoDerrota)
+	actualizarPuntuaciones(partida, estadisticas)
+	if err := sumarResultados(partida, estadisticas); err != nil {
+		t.Fatal(err)
+	}
+	if estadisticas[1].Puntuacion != 1500+factorKProvisional/2 {
+		t.Fatalf("%d puntos tras la última partida provisional", estadisticas[1].Puntuacion)
+	}
+	if provisional(*estadisticas[1]) {
+		t.Fatalf("con %d partidas ya no debería ser provisional", estadisticas[1].Jugadas)
+	}
+
+	// Contra un rival con su misma puntuación, la siguiente victoria ya se puntúa con el factor K normal
+	estadisticas[2].Puntuacion = estadisticas[1].Puntuacion
+	actualizarPuntuaciones(partida, estadisticas)
+	if estadisticas[1].Puntuacion != 1500+factorKProvisional/2+factorK/2 {
+		t.Fatalf("%d puntos tras la primera partida establecida", estadisticas[1].Puntuacion)
+	}
+}
+
+// almacenEspia apunta cómo guarda el servicio cada partida
+type almacenEspia struct {
+	store.GameStore
+	terminadas []int // Movimientos que acompañaron a cada TerminarPartida
+	guardadas  int
+}
+
+func (s *almacenEspia) TerminarPartida(partida *models.Partida, actualizar func(map[uint]*models.Estadistica) error, movimientos ...models.Movimiento) error {
+	s.terminadas = append(s.terminadas, len(movimientos))
+	return s.GameStore.TerminarPartida(partida, actualizar, movimientos...)
+}
+
+func (s *almacenEspia) GuardarPartida(partida *models.Partida, movimientos ...models.Movimiento) error {
+	s.guardadas++
+	return s.GameStore.GuardarPartida(partida, movimientos...)
+}
+
+func TestHistorialSeGuardaAlTerminar(t *testing.T) {
+	almacen := &almacenEspia{GameStore: store.NewMemoria()}
+	service := NewJuegoService(almacen, realtime.NewHub(), 0, mcts.Config{})
+
+	jugadores := []models.Jugador{{Name: "ana", Email: "ana@ejemplo.com"}, {Name: "bea", Email: "bea@ejemplo.com"}}
+	for i := range jugadores {
+		if err := almacen.CrearJugador(&jugadores[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	juego, err := service.CrearJuego(engine.TipoConectaCuatro, &jugadores[0], jugadores)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := juego.Comun().ID
+
+	// Ana llena la columna 0 y Bea la 1: la cuarta ficha de Ana termina la partida
+	columnas := []int{0, 1, 0, 1, 0, 1, 0}
+	for i, columna := range columnas {
+		jugador := jugadores[i%2]
+		if _, _, err := service.HacerMovimiento(engine.TipoConectaCuatro, id, jugador.ID, &engine.MovimientoConecta{Columna: columna}); err != nil {
+			t.Fatalf("movimiento %d: %v", i+1, err)
+		}
+		if i < len(columnas)-1 && len(almacen.terminadas) != 0 {
+			t.Fatalf("la partida se ha dado por terminada en el movimiento %d", i+1)
+		}
+	}
+
+	// El último movimiento, las estadísticas y el historial van juntos en TerminarPartida
+	if len(almacen.terminadas) != 1 || almacen.terminadas[0] != 1 {
+		t.Fatalf("TerminarPartida llamado %v, se esperaba una vez con el último movimiento", almacen.terminadas)
+	}
+	if almacen.guardadas != len(columnas)-1 {
+		t.Fatalf("%d partidas guardadas, se esperaban %d", almacen.guardadas, len(columnas)-1)
+	}
+
+	esperados := []struct {
+		resultado  string
+		puntuacion int
+	}{{models.ResultadoVictoria, 1520}, {models.ResultadoDerrota, 1480}}
+	for i, esperado := range esperados {
+		historial, err := almacen.ListarHistorialPuntuacion(jugadores[i].ID, engine.TipoConectaCuatro, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(historial) != 1 {
+			t.Fatalf("%s tiene %d entradas en el historial, se esperaba una", jugadores[i].Name, len(historial))
+		}
+		cambio := historial[0]
+		if cambio.PartidaID != id || cambio.Resultado != esperado.resultado ||
+			cambio.Anterior != models.PuntuacionInicial || cambio.Puntuacion != esperado.puntuacion {
+			t.Fatalf("historial de %s: %+v", jugadores[i].Name, cambio)
+		}
+
+		estadisticas, err := almacen.ListarEstadisticas(jugadores[i].ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(estadisticas) != 1 || estadisticas[0].Puntuacion != cambio.Puntuacion || estadisticas[0].Jugadas != 1 {
+			t.Fatalf("estadísticas de %s: %+v", jugadores[i].Name, estadisticas)
+		}
+	}
+
+	// Las consultas posteriores no vuelven a apuntar la partida
+	if _, err := service.ObtenerJuego(engine.TipoConectaCuatro, id); err != nil {
+		t.Fatal(err)
+	}
+	if len(almacen.terminadas) != 1 {
+		t.Fatalf("TerminarPartida llamado %d veces", len(almacen.terminadas))
+	}
+}
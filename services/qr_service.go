@@ -0,0 +1,152 @@
+package services
+
+import (
+	"errors"
+	"juego/models"
+	"juego/store"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrQRNoEncontrado = errors.New("Código QR no encontrado")
+	ErrQRCaducado     = errors.New("El código QR ha caducado")
+	ErrQRUsado        = errors.New("El código QR ya se ha usado")
+	ErrQRNoConfirmado = errors.New("El código QR aún no se ha confirmado")
+	ErrQRSecreto      = errors.New("El secreto no corresponde al código QR")
+)
+
+// QRService gestiona el inicio de sesión con QR: un dispositivo sin sesión
+// genera un código, el móvil del jugador (ya identificado) lo confirma y el
+// primer dispositivo lo cambia por una sesión. El código pasa por
+// pending → confirmed → consumed, o expired si se acaba su tiempo
+type QRService struct {
+	Store         store.GameStore
+	SesionService *SesionService
+	Duracion      time.Duration
+	mutex         sync.Mutex // Evita que un mismo código se confirme o consuma dos veces
+}
+
+func NewQRService(gameStore store.GameStore, sesionService *SesionService, duracion time.Duration) *QRService {
+	return &QRService{Store: gameStore, SesionService: sesionService, Duracion: duracion}
+}
+
+// GenerarCodigo crea un código pendiente. El secreto solo se devuelve aquí y
+// hace falta para iniciar sesión, así que ver el QR no basta para robar la sesión
+func (service *QRService) GenerarCodigo() (*models.CodigoQR, string, error) {
+	ahora := time.Now()
+	// Se aprovecha para quitar los códigos caducados hace tiempo
+	if err := service.Store.BorrarCodigosQRCaducados(ahora.Add(-service.Duracion)); err != nil {
+		return nil, "", err
+	}
+
+	secreto, err := nuevoToken()
+	if err != nil {
+		return nil, "", err
+	}
+	codigo := models.CodigoQR{
+		Codigo:      uuid.New().String(),
+		SecretoHash: hashToken(secreto),
+		Estado:      models.QRPendiente,
+		ExpiraEn:    ahora.Add(service.Duracion),
+		CreadoEn:    ahora,
+	}
+	if err := service.Store.CrearCodigoQR(&codigo); err != nil {
+		return nil, "", err
+	}
+	return &codigo, secreto, nil
+}
+
+// EstadoCodigo devuelve el código con su estado al día
+func (service *QRService) EstadoCodigo(codigo string) (*models.CodigoQR, error) {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+	return service.cargar(codigo)
+}
+
+// ConfirmarCodigo asocia un código pendiente al jugador identificado en el móvil
+func (service *QRService) ConfirmarCodigo(codigo string, jugador *models.Jugador) (*models.CodigoQR, error) {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	codigoQR, err := service.cargar(codigo)
+	if err != nil {
+		return nil, err
+	}
+	switch codigoQR.Estado {
+	case models.QRCaducado:
+		return nil, ErrQRCaducado
+	case models.QRConfirmado, models.QRConsumido:
+		return nil, ErrQRUsado
+	}
+
+	ahora := time.Now()
+	codigoQR.Estado = models.QRConfirmado
+	codigoQR.JugadorID = &jugador.ID
+	codigoQR.ConfirmadoEn = &ahora
+	if err := service.Store.GuardarCodigoQR(codigoQR); err != nil {
+		return nil, err
+	}
+	return codigoQR, nil
+}
+
+// IniciarSesion cambia un código confirmado por una sesión del jugador que lo
+// confirmó. El código queda consumido y no se puede volver a usar
+func (service *QRService) IniciarSesion(codigo, secreto string) (*Tokens, *models.Jugador, error) {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	codigoQR, err := service.cargar(codigo)
+	if err != nil {
+		return nil, nil, err
+	}
+	if codigoQR.SecretoHash != hashToken(secreto) {
+		return nil, nil, ErrQRSecreto
+	}
+	switch codigoQR.Estado {
+	case models.QRPendiente:
+		return nil, nil, ErrQRNoConfirmado
+	case models.QRCaducado:
+		return nil, nil, ErrQRCaducado
+	case models.QRConsumido:
+		return nil, nil, ErrQRUsado
+	}
+
+	codigoQR.Estado = models.QRConsumido
+	if err := service.Store.GuardarCodigoQR(codigoQR); err != nil {
+		return nil, nil, err
+	}
+
+	jugador, err := service.Store.ObtenerJugador(*codigoQR.JugadorID)
+	if err != nil {
+		return nil, nil, err
+	}
+	jugador = &models.Jugador{ID: jugador.ID, Name: jugador.Name, Email: jugador.Email}
+	tokens, err := service.SesionService.IniciarSesion(jugador)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tokens, jugador, nil
+}
+
+// cargar busca un código y lo marca como caducado si se le ha pasado el tiempo
+// sin llegar a usarse; requiere el mutex
+func (service *QRService) cargar(codigo string) (*models.CodigoQR, error) {
+	codigoQR, err := service.Store.ObtenerCodigoQR(codigo)
+	if err != nil {
+		if errors.Is(err, store.ErrNoEncontrado) {
+			return nil, ErrQRNoEncontrado
+		}
+		return nil, err
+	}
+
+	if codigoQR.Estado != models.QRConsumido && codigoQR.Estado != models.QRCaducado && time.Now().After(codigoQR.ExpiraEn) {
+		codigoQR.Estado = models.QRCaducado
+		if err := service.Store.GuardarCodigoQR(codigoQR); err != nil {
+			return nil, err
+		}
+	}
+	return codigoQR, nil
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"juego/engine"
+	"juego/models"
+	"log"
+	"time"
+)
+
+// nuevoReloj prepara el reloj parado de una sala con control de tiempo; empieza
+// a correr cuando se ocupan todos los asientos
+func nuevoReloj(asientos int, sala *models.Sala) *models.Reloj {
+	restante := make([]int64, asientos)
+	for i := range restante {
+		restante[i] = int64(sala.TiempoInicial) * 1000
+	}
+	return &models.Reloj{Restante: restante, Incremento: int64(sala.Incremento) * 1000}
+}
+
+// tiempoRestante devuelve lo que le queda al asiento con el turno contando el
+// turno en curso. Es falso si la partida no tiene un reloj corriendo
+func tiempoRestante(reglas engine.Rules, juego engine.Game) (int, time.Duration, bool) {
+	reloj := juego.Comun().Reloj
+	if reloj == nil || reloj.Desde.IsZero() || juego.Comun().Estado != models.EstadoEnProgreso {
+		return 0, 0, false
+	}
+	turno := reglas.CurrentPlayer(juego)
+	if turno < 0 || turno >= len(reloj.Restante) {
+		return 0, 0, false
+	}
+	return turno, time.Duration(reloj.Restante[turno])*time.Millisecond - time.Since(reloj.Desde), true
+}
+
+// vencida indica si la partida ha acabado sola desde la última vez que se guardó:
+// el que tiene el turno ha agotado su tiempo o lleva demasiado tiempo parada
+func (service *JuegoService) vencida(reglas engine.Rules, juego engine.Game) bool {
+	if _, restante, ok := tiempoRestante(reglas, juego); ok && restante <= 0 {
+		return true
+	}
+	return service.caducada(juego)
+}
+
+// vencer cierra una partida vencida. Quien agota su reloj pierde: con dos
+// asientos gana el otro y con más la partida queda abandonada
+func (service *JuegoService) vencer(reglas engine.Rules, juego engine.Game) {
+	turno, restante, ok := tiempoRestante(reglas, juego)
+	if !ok || restante > 0 {
+		abandonar(juego)
+		return
+	}
+
+	comun := juego.Comun()
+	comun.Reloj.Restante[turno] = 0
+	comun.Reloj.Desde = time.Time{}
+	participantes := juego.Participantes()
+	if len(participantes) != 2 {
+		abandonar(juego)
+		return
+	}
+	ganador := participantes[1-turno]
+	comun.Estado = models.EstadoTerminado
+	comun.Ganador = &ganador
+	comun.Actualizado = time.Now()
+}
+
+// jugar aplica un movimiento con engine.Play y, si la partida lleva reloj,
+// descuenta el tiempo gastado en el turno, suma el incremento y pone a correr
+// el del siguiente asiento
+func jugar(reglas engine.Rules, juego engine.Game, asiento int, movimiento engine.Move) (engine.Outcome, error) {
+	turno, restante, conReloj := tiempoRestante(reglas, juego)
+	resultado, err := engine.Play(reglas, juego, asiento, movimiento)
+	if err != nil || !conReloj {
+		return resultado, err
+	}
+
+	reloj := juego.Comun().Reloj
+	reloj.Restante[turno] = restante.Milliseconds() + reloj.Incremento
+	reloj.Desde = time.Now()
+	if resultado.Finished {
+		reloj.Desde = time.Time{}
+	}
+	return resultado, nil
+}
+
+// vigilarReloj programa la comprobación del reloj del turno en curso, para que
+// la derrota por tiempo se guarde y se publique aunque nadie vuelva a pedir la
+// partida. Si para entonces ya se ha movido, la comprobación no cambia nada
+func (service *JuegoService) vigilarReloj(reglas engine.Rules, juego engine.Game) {
+	_, restante, ok := tiempoRestante(reglas, juego)
+	if !ok {
+		return
+	}
+	tipo, id := juego.Comun().TipoJuego, juego.Comun().ID
+	time.AfterFunc(restante+10*time.Millisecond, func() {
+		if _, err := service.ObtenerJuego(tipo, id); err != nil {
+			log.Printf("reloj: %s %s: %v", tipo, id, err)
+		}
+	})
+}
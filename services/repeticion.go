@@ -0,0 +1,94 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"juego/engine"
+	"juego/models"
+)
+
+var ErrPasoFueraDeRango = errors.New("El paso pedido no existe en esta partida")
+
+// Repeticion es el historial de una partida junto con la posición en un paso concreto
+type Repeticion struct {
+	PartidaID   string              `json:"partida_id"`
+	TipoJuego   string              `json:"tipo_juego"`
+	Total       int                 `json:"total"`       // Número de movimientos del historial
+	Paso        int                 `json:"paso"`        // Movimientos aplicados en Juego (0 = posición inicial)
+	Movimientos []models.Movimiento `json:"movimientos"` // Historial completo ordenado
+	Juego       engine.Game         `json:"juego"`       // Posición tras el movimiento número Paso
+}
+
+// RepetirJuego reconstruye la posición de la partida tras paso movimientos,
+// partiendo del estado inicial; con paso negativo devuelve la posición final
+func (service *JuegoService) RepetirJuego(tipo, id string, paso int) (*Repeticion, error) {
+	reglas, err := service.Reglas(tipo)
+	if err != nil {
+		return nil, err
+	}
+	_, partida, err := service.cargar(tipo, id)
+	if err != nil {
+		return nil, err
+	}
+	movimientos, err := service.Store.ListarMovimientos(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if paso < 0 {
+		paso = len(movimientos)
+	}
+	if paso > len(movimientos) {
+		return nil, ErrPasoFueraDeRango
+	}
+
+	juego, err := engine.Decode(reglas, []byte(partida.Tablero.Inicial))
+	if err != nil {
+		return nil, err
+	}
+	for _, movimiento := range movimientos[:paso] {
+		if juego, err = reproducir(reglas, juego, movimiento); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Repeticion{
+		PartidaID:   partida.ID,
+		TipoJuego:   partida.TipoJuego,
+		Total:       len(movimientos),
+		Paso:        paso,
+		Movimientos: movimientos,
+		Juego:       juego,
+	}, nil
+}
+
+// reproducir aplica una entrada del historial y comprueba que se llega al mismo estado
+func reproducir(reglas engine.Rules, juego engine.Game, movimiento models.Movimiento) (engine.Game, error) {
+	switch movimiento.Tipo {
+	case models.MovimientoReinicio, models.MovimientoUnion:
+		reiniciado, err := engine.Decode(reglas, []byte(movimiento.Datos))
+		if err != nil {
+			return nil, err
+		}
+		juego = reiniciado
+	default:
+		jugada := reglas.NewMove()
+		if err := json.Unmarshal([]byte(movimiento.Datos), jugada); err != nil {
+			return nil, err
+		}
+		if _, err := engine.Play(reglas, juego, movimiento.Asiento, jugada); err != nil {
+			return nil, fmt.Errorf("el movimiento %d no se puede repetir: %w", movimiento.Numero, err)
+		}
+		juego.Comun().Actualizado = movimiento.CreadoEn
+	}
+
+	hash, err := engine.HashState(juego)
+	if err != nil {
+		return nil, err
+	}
+	if hash != movimiento.HashEstado {
+		return nil, fmt.Errorf("el movimiento %d no reproduce el estado guardado", movimiento.Numero)
+	}
+	return juego, nil
+}
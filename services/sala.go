@@ -0,0 +1,134 @@
+package services
+
+import (
+	"errors"
+	"juego/engine"
+	"juego/models"
+	"time"
+)
+
+var (
+	ErrAjustesSala  = errors.New("Ajustes de sala no válidos")
+	ErrNoAnfitrion  = errors.New("Solo el anfitrión puede cerrar la sala")
+	ErrSalaEmpezada = errors.New("La partida de esta sala ya ha empezado")
+	ErrSinTurnos    = errors.New("Este juego no tiene turnos, así que no admite control de tiempo")
+	ErrVariante     = errors.New("Variante no válida para este juego")
+)
+
+// Límites de los ajustes de una sala
+const (
+	maxTiempoInicial = 24 * 60 * 60 // Un día por jugador
+	maxIncremento    = 60 * 60
+)
+
+// variantes son las variantes que se pueden elegir en una sala de cada tipo de
+// juego y el juego que se crea con cada una. Sin variante se juega el tipo pedido
+var variantes = map[string]map[string]string{
+	engine.TipoCuatroEnRaya: {
+		"clasica":     engine.TipoCuatroEnRaya,
+		"desde_borde": engine.TipoDesdeElBorde,
+	},
+}
+
+// SalaLobby es una sala tal como aparece en el listado del lobby
+type SalaLobby struct {
+	ID             string                `json:"id"`
+	TipoJuego      string                `json:"tipo_juego"`
+	Codigo         string                `json:"codigo"`
+	Sala           models.Sala           `json:"sala"`
+	Participantes  []models.Participante `json:"participantes"` // Asientos ocupados
+	AsientosLibres int                   `json:"asientos_libres"`
+	CreadoEn       time.Time             `json:"creado_en"`
+}
+
+// CrearSala crea una partida que espera jugadores con el anfitrión sentado en el
+// primer asiento. Empieza sola en cuanto se ocupan todos los asientos, con las
+// reglas de la variante elegida (la partida es ya del tipo de la variante). Con
+// TiempoInicial la partida lleva reloj, y quien lo agota en su turno pierde
+func (service *JuegoService) CrearSala(tipo string, anfitrion *models.Jugador, sala models.Sala) (engine.Game, error) {
+	if _, err := service.Reglas(tipo); err != nil {
+		return nil, err
+	}
+	if sala.Variante != "" {
+		variante, existe := variantes[tipo][sala.Variante]
+		if !existe {
+			return nil, ErrVariante
+		}
+		tipo = variante
+	}
+	reglas, err := service.Reglas(tipo)
+	if err != nil {
+		return nil, err
+	}
+	if sala.TiempoInicial < 0 || sala.TiempoInicial > maxTiempoInicial ||
+		sala.Incremento < 0 || sala.Incremento > maxIncremento ||
+		(sala.TiempoInicial == 0 && sala.Incremento > 0) {
+		return nil, ErrAjustesSala
+	}
+	// El reloj corre para quien tiene el turno: los juegos en los que puede mover cualquiera no lo admiten
+	if sala.TiempoInicial > 0 && reglas.CurrentPlayer(reglas.NewState("", make([]models.Jugador, reglas.Seats()))) == engine.AnySeat {
+		return nil, ErrSinTurnos
+	}
+	sala.AnfitrionID = anfitrion.ID
+
+	jugadores := []models.Jugador{{ID: anfitrion.ID, Name: anfitrion.Name}}
+	return service.crear(tipo, jugadores, &sala)
+}
+
+// ListarSalas devuelve las salas públicas con asientos libres, las más recientes primero
+func (service *JuegoService) ListarSalas(tipo string, limite, desplazamiento int) ([]SalaLobby, error) {
+	if tipo != "" {
+		if _, err := service.Reglas(tipo); err != nil {
+			return nil, err
+		}
+	}
+
+	partidas, err := service.Store.ListarSalas(tipo, limite, desplazamiento)
+	if err != nil {
+		return nil, err
+	}
+
+	salas := make([]SalaLobby, 0, len(partidas))
+	for _, partida := range partidas {
+		sala := SalaLobby{
+			ID:        partida.ID,
+			TipoJuego: partida.TipoJuego,
+			Sala:      partida.Sala,
+			CreadoEn:  partida.CreadoEn,
+		}
+		if partida.Codigo != nil {
+			sala.Codigo = *partida.Codigo
+		}
+		for _, participante := range partida.Participantes {
+			if participante.JugadorID == 0 {
+				sala.AsientosLibres++
+			} else {
+				sala.Participantes = append(sala.Participantes, participante)
+			}
+		}
+		salas = append(salas, sala)
+	}
+	return salas, nil
+}
+
+// CerrarSala abandona una sala que aún espera jugadores; solo puede hacerlo su anfitrión
+func (service *JuegoService) CerrarSala(tipo, id string, jugadorID uint) (engine.Game, error) {
+	var juego engine.Game
+
+	err := service.actualizar(tipo, id, func(reglas engine.Rules, g engine.Game) (*models.Movimiento, error) {
+		comun := g.Comun()
+		if comun.Sala == nil || comun.Sala.AnfitrionID != jugadorID {
+			return nil, ErrNoAnfitrion
+		}
+		if comun.Estado != models.EstadoEsperando {
+			return nil, ErrSalaEmpezada
+		}
+		abandonar(g)
+		juego = g
+		return nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return juego, nil
+}
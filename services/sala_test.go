@@ -0,0 +1,81 @@
+package services
+
+import (
+	"errors"
+	"juego/engine"
+	"juego/mcts"
+	"juego/models"
+	"juego/realtime"
+	"juego/store"
+	"testing"
+)
+
+// nuevoServicio crea un JuegoService en memoria con los jugadores indicados ya registrados
+func nuevoServicio(t *testing.T, nombres ...string) (*JuegoService, []models.Jugador) {
+	t.Helper()
+	almacen := store.NewMemoria()
+	jugadores := make([]models.Jugador, len(nombres))
+	for i, nombre := range nombres {
+		jugadores[i] = models.Jugador{Name: nombre, Email: nombre + "@ejemplo.com"}
+		if err := almacen.CrearJugador(&jugadores[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewJuegoService(almacen, realtime.NewHub(), 0, mcts.Config{Iteraciones: 200, Semilla: 1}), jugadores
+}
+
+func TestVarianteDeSala(t *testing.T) {
+	casos := []struct {
+		nombre   string
+		tipo     string
+		variante string
+		juego    string
+		err      error
+	}{
+		{"sin variante", engine.TipoCuatroEnRaya, "", engine.TipoCuatroEnRaya, nil},
+		{"clásica", engine.TipoCuatroEnRaya, "clasica", engine.TipoCuatroEnRaya, nil},
+		{"desde el borde", engine.TipoCuatroEnRaya, "desde_borde", engine.TipoDesdeElBorde, nil},
+		{"variante desconocida", engine.TipoCuatroEnRaya, "gigante", "", ErrVariante},
+		{"variante de otro juego", engine.TipoConectaCuatro, "desde_borde", "", ErrVariante},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			service, jugadores := nuevoServicio(t, "ana")
+			juego, err := service.CrearSala(caso.tipo, &jugadores[0], models.Sala{Variante: caso.variante})
+			if !errors.Is(err, caso.err) {
+				t.Fatalf("error %v, se esperaba %v", err, caso.err)
+			}
+			if err != nil {
+				return
+			}
+			if juego.Comun().TipoJuego != caso.juego || juego.Comun().Sala.Variante != caso.variante {
+				t.Fatalf("partida de tipo %q con variante %q", juego.Comun().TipoJuego, juego.Comun().Sala.Variante)
+			}
+		})
+	}
+}
+
+func TestVarianteLlegaALaPartida(t *testing.T) {
+	service, jugadores := nuevoServicio(t, "ana", "bea")
+	sala, err := service.CrearSala(engine.TipoCuatroEnRaya, &jugadores[0], models.Sala{Variante: "desde_borde"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	juego, _, err := service.UnirseJuego(sala.Comun().Codigo, &jugadores[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if juego.Comun().Estado != models.EstadoEnProgreso {
+		t.Fatalf("la partida debería haber empezado al llenarse: %s", juego.Comun().Estado)
+	}
+
+	// Se juega con las reglas de la variante: el interior no se puede ocupar con el borde libre
+	tipo, id := juego.Comun().TipoJuego, juego.Comun().ID
+	_, _, err = service.HacerMovimiento(tipo, id, jugadores[0].ID, &engine.MovimientoDesdeBorde{DestinoX: 1, DestinoY: 1})
+	if !errors.Is(err, engine.ErrBordeExterior) {
+		t.Fatalf("error %v, se esperaba %v", err, engine.ErrBordeExterior)
+	}
+	if _, _, err := service.HacerMovimiento(tipo, id, jugadores[0].ID, &engine.MovimientoDesdeBorde{DestinoX: 0, DestinoY: 0}); err != nil {
+		t.Fatal(err)
+	}
+}
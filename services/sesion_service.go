@@ -0,0 +1,146 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"juego/models"
+	"juego/store"
+	"time"
+)
+
+var (
+	ErrSesionInvalida = errors.New("Sesión no válida o caducada")
+)
+
+// Tokens son las credenciales que recibe el jugador al iniciar sesión
+type Tokens struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"` // Segundos de validez del token de acceso
+}
+
+// SesionService emite y comprueba tokens opacos guardados en el almacenamiento.
+// El token de acceso dura poco; el de refresco sirve para pedir otro par sin contraseña
+type SesionService struct {
+	Store            store.GameStore
+	DuracionAcceso   time.Duration
+	DuracionRefresco time.Duration
+}
+
+func NewSesionService(gameStore store.GameStore, duracionAcceso, duracionRefresco time.Duration) *SesionService {
+	return &SesionService{Store: gameStore, DuracionAcceso: duracionAcceso, DuracionRefresco: duracionRefresco}
+}
+
+// IniciarSesion crea una sesión nueva para el jugador
+func (service *SesionService) IniciarSesion(jugador *models.Jugador) (*Tokens, error) {
+	acceso, err := nuevoToken()
+	if err != nil {
+		return nil, err
+	}
+	refresco, err := nuevoToken()
+	if err != nil {
+		return nil, err
+	}
+
+	ahora := time.Now()
+	sesion := models.Sesion{
+		JugadorID:      jugador.ID,
+		AccesoHash:     hashToken(acceso),
+		RefrescoHash:   hashToken(refresco),
+		AccesoExpira:   ahora.Add(service.DuracionAcceso),
+		RefrescoExpira: ahora.Add(service.DuracionRefresco),
+		CreadoEn:       ahora,
+	}
+	if err := service.Store.CrearSesion(&sesion); err != nil {
+		return nil, err
+	}
+
+	return &Tokens{
+		AccessToken:  acceso,
+		RefreshToken: refresco,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(service.DuracionAcceso.Seconds()),
+	}, nil
+}
+
+// Autenticar devuelve el jugador dueño de un token de acceso vigente
+func (service *SesionService) Autenticar(accessToken string) (*models.Jugador, error) {
+	sesion, err := service.Store.BuscarSesionPorAcceso(hashToken(accessToken))
+	if err != nil {
+		return nil, traducirSesion(err)
+	}
+	if time.Now().After(sesion.AccesoExpira) {
+		return nil, ErrSesionInvalida
+	}
+	return service.jugadorDe(sesion)
+}
+
+// Refrescar cambia un token de refresco vigente por una sesión nueva; el
+// token usado deja de valer para que no se pueda reutilizar
+func (service *SesionService) Refrescar(refreshToken string) (*Tokens, *models.Jugador, error) {
+	sesion, err := service.Store.BuscarSesionPorRefresco(hashToken(refreshToken))
+	if err != nil {
+		return nil, nil, traducirSesion(err)
+	}
+	if err := service.Store.BorrarSesion(sesion.ID); err != nil {
+		return nil, nil, err
+	}
+	if time.Now().After(sesion.RefrescoExpira) {
+		return nil, nil, ErrSesionInvalida
+	}
+
+	jugador, err := service.jugadorDe(sesion)
+	if err != nil {
+		return nil, nil, err
+	}
+	tokens, err := service.IniciarSesion(jugador)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tokens, jugador, nil
+}
+
+// CerrarSesion invalida la sesión a la que pertenece el token de acceso
+func (service *SesionService) CerrarSesion(accessToken string) error {
+	sesion, err := service.Store.BuscarSesionPorAcceso(hashToken(accessToken))
+	if err != nil {
+		return traducirSesion(err)
+	}
+	return service.Store.BorrarSesion(sesion.ID)
+}
+
+// jugadorDe carga el jugador de una sesión sin su contraseña
+func (service *SesionService) jugadorDe(sesion *models.Sesion) (*models.Jugador, error) {
+	jugador, err := service.Store.ObtenerJugador(sesion.JugadorID)
+	if err != nil {
+		return nil, traducirSesion(err)
+	}
+	return &models.Jugador{ID: jugador.ID, Name: jugador.Name, Email: jugador.Email}, nil
+}
+
+// nuevoToken genera 32 bytes aleatorios codificados en base64 para URL
+func nuevoToken() (string, error) {
+	datos := make([]byte, 32)
+	if _, err := rand.Read(datos); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(datos), nil
+}
+
+// hashToken es lo que se guarda de cada token
+func hashToken(token string) string {
+	suma := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(suma[:])
+}
+
+// traducirSesion convierte "no encontrado" en sesión inválida
+func traducirSesion(err error) error {
+	if errors.Is(err, store.ErrNoEncontrado) {
+		return ErrSesionInvalida
+	}
+	return err
+}
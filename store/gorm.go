@@ -0,0 +1,384 @@
+package store
+
+import (
+	"errors"
+	"juego/db"
+	"juego/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Gorm guarda los datos en una base de datos SQL (SQLite en desarrollo, MySQL en producción)
+type Gorm struct {
+	DB *gorm.DB
+}
+
+func NewGorm(db *gorm.DB) *Gorm {
+	return &Gorm{DB: db}
+}
+
+func (s *Gorm) CrearPartida(partida *models.Partida) error {
+	return s.DB.Create(partida).Error
+}
+
+func (s *Gorm) ObtenerPartida(id string) (*models.Partida, error) {
+	var partida models.Partida
+	err := s.DB.
+		Preload("Participantes", func(tx *gorm.DB) *gorm.DB { return tx.Order("asiento") }).
+		Preload("Tablero").
+		Where("id = ?", id).
+		First(&partida).Error
+	if err != nil {
+		return nil, traducirError(err)
+	}
+	return &partida, nil
+}
+
+func (s *Gorm) ListarSalas(tipo string, limite, desplazamiento int) ([]models.Partida, error) {
+	consulta := s.DB.
+		Preload("Participantes", func(tx *gorm.DB) *gorm.DB { return tx.Order("asiento") }).
+		Where("estado = ? AND sala_privada = ?", models.EstadoEsperando, false)
+	if tipo != "" {
+		consulta = consulta.Where("tipo_juego = ?", tipo)
+	}
+
+	var partidas []models.Partida
+	err := consulta.Order("creado_en DESC").Limit(limite).Offset(desplazamiento).Find(&partidas).Error
+	return partidas, err
+}
+
+func (s *Gorm) ListarPartidasJugador(jugadorID uint, filtro FiltroPartidas) ([]models.Partida, int, error) {
+	consulta := func() *gorm.DB {
+		consulta := s.DB.Model(&models.Partida{}).
+			Where("id IN (?)", s.DB.Model(&models.Participante{}).Select("partida_id").Where("jugador_id = ?", jugadorID)).
+			Where("terminado_en IS NOT NULL")
+		if filtro.TipoJuego != "" {
+			consulta = consulta.Where("tipo_juego = ?", filtro.TipoJuego)
+		}
+		if filtro.Desde != nil {
+			consulta = consulta.Where("terminado_en >= ?", *filtro.Desde)
+		}
+		if filtro.Hasta != nil {
+			consulta = consulta.Where("terminado_en < ?", *filtro.Hasta)
+		}
+		return consulta
+	}
+
+	var total int64
+	if err := consulta().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var partidas []models.Partida
+	err := consulta().
+		Preload("Participantes", func(tx *gorm.DB) *gorm.DB { return tx.Order("asiento") }).
+		Order("terminado_en DESC").Limit(filtro.Limite).Offset(filtro.Desplazamiento).
+		Find(&partidas).Error
+	return partidas, int(total), err
+}
+
+func (s *Gorm) BuscarPartidaPorCodigo(codigo string) (*models.Partida, error) {
+	var partida models.Partida
+	if err := s.DB.Select("id").Where("codigo = ?", codigo).First(&partida).Error; err != nil {
+		return nil, traducirError(err)
+	}
+	return s.ObtenerPartida(partida.ID)
+}
+
+func (s *Gorm) GuardarPartida(partida *models.Partida, movimientos ...models.Movimiento) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		return guardarPartida(tx, partida, movimientos)
+	})
+}
+
+func (s *Gorm) TerminarPartida(partida *models.Partida, actualizar func(map[uint]*models.Estadistica) error, movimientos ...models.Movimiento) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := guardarPartida(tx, partida, movimientos); err != nil {
+			return err
+		}
+
+		var ids []uint
+		for _, participante := range partida.Participantes {
+			if participante.JugadorID != 0 {
+				ids = append(ids, participante.JugadorID)
+				// Se crean antes de leerlas para que dos partidas a la vez no choquen al insertar
+				err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+					Create(&models.Estadistica{JugadorID: participante.JugadorID, TipoJuego: partida.TipoJuego, Puntuacion: models.PuntuacionInicial}).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		var lista []models.Estadistica
+		consulta := tx.Where("tipo_juego = ? AND jugador_id IN ?", partida.TipoJuego, ids)
+		if tx.Dialector.Name() != "sqlite" { // SQLite bloquea la base entera al escribir y no admite FOR UPDATE
+			consulta = consulta.Clauses(clause.Locking{Strength: "UPDATE"})
+		}
+		if err := consulta.Find(&lista).Error; err != nil {
+			return err
+		}
+		estadisticas := make(map[uint]*models.Estadistica, len(lista))
+		anteriores := make(map[uint]int, len(lista))
+		for i := range lista {
+			estadisticas[lista[i].JugadorID] = &lista[i]
+			anteriores[lista[i].JugadorID] = lista[i].Puntuacion
+		}
+		if err := actualizar(estadisticas); err != nil {
+			return err
+		}
+		for _, estadistica := range estadisticas {
+			if err := tx.Save(estadistica).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(historialPuntuacion(partida, estadisticas, anteriores)).Error
+	})
+}
+
+// guardarPartida actualiza la partida y añade los movimientos dentro de una transacción
+func guardarPartida(tx *gorm.DB, partida *models.Partida, movimientos []models.Movimiento) error {
+	err := tx.Model(partida).
+		Select("Estado", "GanadorID", "Actualizado", "TerminadoEn", "Movimientos").
+		Updates(partida).Error
+	if err != nil {
+		return err
+	}
+	for _, participante := range partida.Participantes {
+		err := tx.Model(&models.Participante{}).
+			Where("partida_id = ? AND asiento = ?", partida.ID, participante.Asiento).
+			Updates(map[string]interface{}{
+				"jugador_id": participante.JugadorID,
+				"nombre":     participante.Nombre,
+				"resultado":  participante.Resultado,
+			}).Error
+		if err != nil {
+			return err
+		}
+	}
+	err = tx.Model(&models.TableroPartida{}).
+		Where("partida_id = ?", partida.ID).
+		Update("datos", partida.Tablero.Datos).Error
+	if err != nil {
+		return err
+	}
+	if len(movimientos) > 0 {
+		return tx.Create(&movimientos).Error
+	}
+	return nil
+}
+
+func (s *Gorm) ListarClasificacion(tipo, orden string, desde *time.Time, limite, desplazamiento int) ([]models.PuestoClasificacion, int, error) {
+	var total int64
+	if err := s.clasificacion(tipo, desde).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var puestos []models.PuestoClasificacion
+	err := s.clasificacion(tipo, desde).Order(columnaOrden(orden) + " DESC").Order("jugador_id").
+		Limit(limite).Offset(desplazamiento).Scan(&puestos).Error
+	if err != nil || len(puestos) == 0 {
+		return puestos, int(total), err
+	}
+
+	// El primero de la página puede empatar con los de la anterior; los demás se numeran a partir de él
+	if puestos[0].Posicion, err = s.posicion(tipo, orden, desde, puestos[0]); err != nil {
+		return nil, 0, err
+	}
+	for i := 1; i < len(puestos); i++ {
+		puestos[i].Posicion = desplazamiento + i + 1
+		if valorClasificacion(puestos[i], orden) == valorClasificacion(puestos[i-1], orden) {
+			puestos[i].Posicion = puestos[i-1].Posicion
+		}
+	}
+	return puestos, int(total), nil
+}
+
+func (s *Gorm) PosicionClasificacion(tipo, orden string, desde *time.Time, jugadorID uint) (*models.PuestoClasificacion, error) {
+	var puestos []models.PuestoClasificacion
+	if err := s.clasificacion(tipo, desde).Where("jugador_id = ?", jugadorID).Limit(1).Scan(&puestos).Error; err != nil {
+		return nil, err
+	}
+	if len(puestos) == 0 {
+		return nil, ErrNoEncontrado
+	}
+
+	var err error
+	puestos[0].Posicion, err = s.posicion(tipo, orden, desde, puestos[0])
+	return &puestos[0], err
+}
+
+// posicion cuenta cuántos jugadores tienen mejor valor que el puesto
+func (s *Gorm) posicion(tipo, orden string, desde *time.Time, puesto models.PuestoClasificacion) (int, error) {
+	var mejores int64
+	err := s.clasificacion(tipo, desde).Where(columnaOrden(orden)+" > ?", valorClasificacion(puesto, orden)).Count(&mejores).Error
+	return int(mejores) + 1, err
+}
+
+// clasificacion prepara la consulta de los jugadores que entran en una
+// clasificación, con las columnas de PuestoClasificacion. Sin periodo se leen
+// las estadísticas acumuladas; con periodo se cuentan las partidas del
+// historial de puntuación terminadas desde esa fecha
+func (s *Gorm) clasificacion(tipo string, desde *time.Time) *gorm.DB {
+	var jugadores *gorm.DB
+	if desde == nil {
+		jugadores = s.DB.Table("jugador_estadisticas AS e").
+			Select("e.jugador_id, j.Name AS nombre, e.puntuacion, e.victorias, e.jugadas").
+			Joins("JOIN jugador j ON j.id = e.jugador_id").
+			Where("e.tipo_juego = ? AND e.jugadas > 0", tipo)
+	} else {
+		periodo := s.DB.Table("jugador_puntuaciones").
+			Select("jugador_id, COUNT(*) AS jugadas, SUM(CASE WHEN resultado = ? THEN 1 ELSE 0 END) AS victorias", models.ResultadoVictoria).
+			Where("tipo_juego = ? AND creado_en >= ?", tipo, *desde).
+			Group("jugador_id")
+		jugadores = s.DB.Table("(?) AS p", periodo).
+			Select("p.jugador_id, j.Name AS nombre, e.puntuacion, p.victorias, p.jugadas").
+			Joins("JOIN jugador_estadisticas e ON e.jugador_id = p.jugador_id AND e.tipo_juego = ?", tipo).
+			Joins("JOIN jugador j ON j.id = p.jugador_id")
+	}
+	return s.DB.Table("(?) AS c", jugadores)
+}
+
+// columnaOrden es la columna de la clasificación por la que se ordena
+func columnaOrden(orden string) string {
+	if orden == models.OrdenVictorias {
+		return "victorias"
+	}
+	return "puntuacion"
+}
+
+func (s *Gorm) ListarHistorialPuntuacion(jugadorID uint, tipo string, limite int) ([]models.HistorialPuntuacion, error) {
+	var historial []models.HistorialPuntuacion
+	err := s.DB.Where("jugador_id = ? AND tipo_juego = ?", jugadorID, tipo).
+		Order("id DESC").Limit(limite).Find(&historial).Error
+	return historial, err
+}
+
+func (s *Gorm) ListarEstadisticas(jugadorID uint) ([]models.Estadistica, error) {
+	var estadisticas []models.Estadistica
+	err := s.DB.Where("jugador_id = ?", jugadorID).Order("tipo_juego").Find(&estadisticas).Error
+	return estadisticas, err
+}
+
+func (s *Gorm) ListarMovimientos(partidaID string) ([]models.Movimiento, error) {
+	var movimientos []models.Movimiento
+	err := s.DB.Where("partida_id = ?", partidaID).Order("numero").Find(&movimientos).Error
+	return movimientos, err
+}
+
+func (s *Gorm) CrearMensaje(mensaje *models.MensajeChat) error {
+	return s.DB.Create(mensaje).Error
+}
+
+func (s *Gorm) ListarMensajes(partidaID, canal string, antesDe uint, limite int) ([]models.MensajeChat, error) {
+	consulta := s.DB.Where("partida_id = ? AND canal = ?", partidaID, canal)
+	if antesDe > 0 {
+		consulta = consulta.Where("id < ?", antesDe)
+	}
+
+	var mensajes []models.MensajeChat
+	if err := consulta.Order("id DESC").Limit(limite).Find(&mensajes).Error; err != nil {
+		return nil, err
+	}
+	// Se piden los más nuevos primero para aplicar el límite, pero se devuelven en orden de lectura
+	for i, j := 0, len(mensajes)-1; i < j; i, j = i+1, j-1 {
+		mensajes[i], mensajes[j] = mensajes[j], mensajes[i]
+	}
+	return mensajes, nil
+}
+
+func (s *Gorm) Silenciar(silencio models.Silencio) error {
+	return s.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&silencio).Error
+}
+
+func (s *Gorm) QuitarSilencio(silencio models.Silencio) error {
+	return s.DB.
+		Where("partida_id = ? AND jugador_id = ? AND silenciado_id = ?", silencio.PartidaID, silencio.JugadorID, silencio.SilenciadoID).
+		Delete(&models.Silencio{}).Error
+}
+
+func (s *Gorm) ListarSilencios(partidaID string) ([]models.Silencio, error) {
+	var silencios []models.Silencio
+	err := s.DB.Where("partida_id = ?", partidaID).Find(&silencios).Error
+	return silencios, err
+}
+
+func (s *Gorm) CrearJugador(jugador *models.Jugador) error {
+	return s.DB.Create(jugador).Error
+}
+
+func (s *Gorm) ObtenerJugador(id uint) (*models.Jugador, error) {
+	var jugador models.Jugador
+	if err := s.DB.First(&jugador, id).Error; err != nil {
+		return nil, traducirError(err)
+	}
+	return &jugador, nil
+}
+
+func (s *Gorm) BuscarJugadorPorEmail(email string) (*models.Jugador, error) {
+	var jugador models.Jugador
+	if err := s.DB.Where("Email = ?", email).First(&jugador).Error; err != nil {
+		return nil, traducirError(err)
+	}
+	return &jugador, nil
+}
+
+func (s *Gorm) CrearSesion(sesion *models.Sesion) error {
+	return s.DB.Create(sesion).Error
+}
+
+func (s *Gorm) BuscarSesionPorAcceso(hash string) (*models.Sesion, error) {
+	return s.buscarSesion("acceso_hash = ?", hash)
+}
+
+func (s *Gorm) BuscarSesionPorRefresco(hash string) (*models.Sesion, error) {
+	return s.buscarSesion("refresco_hash = ?", hash)
+}
+
+func (s *Gorm) BorrarSesion(id uint) error {
+	return s.DB.Delete(&models.Sesion{}, id).Error
+}
+
+func (s *Gorm) buscarSesion(condicion string, hash string) (*models.Sesion, error) {
+	var sesion models.Sesion
+	if err := s.DB.Where(condicion, hash).First(&sesion).Error; err != nil {
+		return nil, traducirError(err)
+	}
+	return &sesion, nil
+}
+
+func (s *Gorm) CrearCodigoQR(codigo *models.CodigoQR) error {
+	return s.DB.Create(codigo).Error
+}
+
+func (s *Gorm) ObtenerCodigoQR(codigo string) (*models.CodigoQR, error) {
+	var codigoQR models.CodigoQR
+	if err := s.DB.Where("codigo = ?", codigo).First(&codigoQR).Error; err != nil {
+		return nil, traducirError(err)
+	}
+	return &codigoQR, nil
+}
+
+func (s *Gorm) GuardarCodigoQR(codigo *models.CodigoQR) error {
+	return s.DB.Save(codigo).Error
+}
+
+func (s *Gorm) BorrarCodigosQRCaducados(antes time.Time) error {
+	return s.DB.Where("expira_en < ?", antes).Delete(&models.CodigoQR{}).Error
+}
+
+func (s *Gorm) Close() error {
+	return db.Close()
+}
+
+// traducirError convierte el "no encontrado" de GORM en el error común del paquete
+func traducirError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNoEncontrado
+	}
+	return err
+}
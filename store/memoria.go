@@ -0,0 +1,483 @@
+package store
+
+import (
+	"juego/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Memoria guarda los datos en mapas; se pierde al reiniciar y sirve para pruebas
+type Memoria struct {
+	mutex        sync.RWMutex
+	partidas     map[string]models.Partida
+	movimientos  map[string][]models.Movimiento
+	jugadores    map[uint]models.Jugador
+	sesiones     map[uint]models.Sesion
+	codigosQR    map[string]models.CodigoQR
+	mensajes     map[string][]models.MensajeChat // Por partida, en orden de ID
+	silencios    map[models.Silencio]bool        // Sin ID: la clave es partida, jugador y silenciado
+	estadisticas map[claveEstadistica]models.Estadistica
+	historial    []models.HistorialPuntuacion // En orden de ID
+	ultimoID     map[string]uint              // Último ID asignado en cada tabla
+}
+
+// claveEstadistica identifica las estadísticas de un jugador en un tipo de juego
+type claveEstadistica struct {
+	jugadorID uint
+	tipo      string
+}
+
+func NewMemoria() *Memoria {
+	return &Memoria{
+		partidas:     make(map[string]models.Partida),
+		movimientos:  make(map[string][]models.Movimiento),
+		jugadores:    make(map[uint]models.Jugador),
+		sesiones:     make(map[uint]models.Sesion),
+		codigosQR:    make(map[string]models.CodigoQR),
+		mensajes:     make(map[string][]models.MensajeChat),
+		silencios:    make(map[models.Silencio]bool),
+		estadisticas: make(map[claveEstadistica]models.Estadistica),
+		ultimoID:     make(map[string]uint),
+	}
+}
+
+func (s *Memoria) CrearPartida(partida *models.Partida) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.partidas[partida.ID] = copiarPartida(*partida)
+	return nil
+}
+
+func (s *Memoria) ObtenerPartida(id string) (*models.Partida, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	partida, existe := s.partidas[id]
+	if !existe {
+		return nil, ErrNoEncontrado
+	}
+	copia := copiarPartida(partida)
+	return &copia, nil
+}
+
+func (s *Memoria) ListarSalas(tipo string, limite, desplazamiento int) ([]models.Partida, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var salas []models.Partida
+	for _, partida := range s.partidas {
+		if partida.Estado == models.EstadoEsperando && !partida.Sala.Privada && (tipo == "" || partida.TipoJuego == tipo) {
+			salas = append(salas, copiarPartida(partida))
+		}
+	}
+	sort.Slice(salas, func(i, j int) bool { return salas[i].CreadoEn.After(salas[j].CreadoEn) })
+
+	if desplazamiento >= len(salas) {
+		return nil, nil
+	}
+	salas = salas[desplazamiento:]
+	if limite < len(salas) {
+		salas = salas[:limite]
+	}
+	return salas, nil
+}
+
+func (s *Memoria) ListarPartidasJugador(jugadorID uint, filtro FiltroPartidas) ([]models.Partida, int, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var partidas []models.Partida
+	for _, partida := range s.partidas {
+		terminada := partida.TerminadoEn
+		if terminada == nil ||
+			(filtro.TipoJuego != "" && partida.TipoJuego != filtro.TipoJuego) ||
+			(filtro.Desde != nil && terminada.Before(*filtro.Desde)) ||
+			(filtro.Hasta != nil && !terminada.Before(*filtro.Hasta)) {
+			continue
+		}
+		for _, participante := range partida.Participantes {
+			if participante.JugadorID == jugadorID {
+				partidas = append(partidas, copiarPartida(partida))
+				break
+			}
+		}
+	}
+	sort.Slice(partidas, func(i, j int) bool { return partidas[i].TerminadoEn.After(*partidas[j].TerminadoEn) })
+
+	total := len(partidas)
+	if filtro.Desplazamiento >= total {
+		return nil, total, nil
+	}
+	partidas = partidas[filtro.Desplazamiento:]
+	if filtro.Limite < len(partidas) {
+		partidas = partidas[:filtro.Limite]
+	}
+	return partidas, total, nil
+}
+
+func (s *Memoria) BuscarPartidaPorCodigo(codigo string) (*models.Partida, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for _, partida := range s.partidas {
+		if partida.Codigo != nil && *partida.Codigo == codigo {
+			copia := copiarPartida(partida)
+			return &copia, nil
+		}
+	}
+	return nil, ErrNoEncontrado
+}
+
+func (s *Memoria) GuardarPartida(partida *models.Partida, movimientos ...models.Movimiento) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.guardarPartida(partida, movimientos)
+}
+
+func (s *Memoria) TerminarPartida(partida *models.Partida, actualizar func(map[uint]*models.Estadistica) error, movimientos ...models.Movimiento) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, existe := s.partidas[partida.ID]; !existe {
+		return ErrNoEncontrado
+	}
+
+	// Se trabaja sobre copias para no dejar nada a medias si actualizar falla
+	estadisticas := make(map[uint]*models.Estadistica)
+	anteriores := make(map[uint]int)
+	for _, participante := range partida.Participantes {
+		if participante.JugadorID == 0 {
+			continue
+		}
+		estadistica, existe := s.estadisticas[claveEstadistica{participante.JugadorID, partida.TipoJuego}]
+		if !existe {
+			estadistica = models.Estadistica{JugadorID: participante.JugadorID, TipoJuego: partida.TipoJuego, Puntuacion: models.PuntuacionInicial}
+		}
+		estadisticas[participante.JugadorID] = &estadistica
+		anteriores[participante.JugadorID] = estadistica.Puntuacion
+	}
+	if err := actualizar(estadisticas); err != nil {
+		return err
+	}
+
+	for _, cambio := range historialPuntuacion(partida, estadisticas, anteriores) {
+		cambio.ID = s.nuevoID("historial_puntuacion")
+		s.historial = append(s.historial, cambio)
+	}
+
+	for _, estadistica := range estadisticas {
+		if estadistica.ID == 0 {
+			estadistica.ID = s.nuevoID("estadisticas")
+		}
+		s.estadisticas[claveEstadistica{estadistica.JugadorID, estadistica.TipoJuego}] = *estadistica
+	}
+	return s.guardarPartida(partida, movimientos)
+}
+
+func (s *Memoria) ListarClasificacion(tipo, orden string, desde *time.Time, limite, desplazamiento int) ([]models.PuestoClasificacion, int, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	puestos := s.clasificacion(tipo, orden, desde)
+	total := len(puestos)
+	if desplazamiento >= total {
+		return nil, total, nil
+	}
+	puestos = puestos[desplazamiento:]
+	if limite < len(puestos) {
+		puestos = puestos[:limite]
+	}
+	return puestos, total, nil
+}
+
+func (s *Memoria) PosicionClasificacion(tipo, orden string, desde *time.Time, jugadorID uint) (*models.PuestoClasificacion, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, puesto := range s.clasificacion(tipo, orden, desde) {
+		if puesto.JugadorID == jugadorID {
+			return &puesto, nil
+		}
+	}
+	return nil, ErrNoEncontrado
+}
+
+// clasificacion calcula la clasificación completa ya ordenada y numerada; requiere el mutex
+func (s *Memoria) clasificacion(tipo, orden string, desde *time.Time) []models.PuestoClasificacion {
+	var puestos []models.PuestoClasificacion
+	if desde == nil {
+		for clave, estadistica := range s.estadisticas {
+			if clave.tipo == tipo && estadistica.Jugadas > 0 {
+				puestos = append(puestos, models.PuestoClasificacion{
+					JugadorID:  clave.jugadorID,
+					Puntuacion: estadistica.Puntuacion,
+					Victorias:  estadistica.Victorias,
+					Jugadas:    estadistica.Jugadas,
+				})
+			}
+		}
+	} else {
+		indices := make(map[uint]int)
+		for _, cambio := range s.historial {
+			if cambio.TipoJuego != tipo || cambio.CreadoEn.Before(*desde) {
+				continue
+			}
+			indice, existe := indices[cambio.JugadorID]
+			if !existe {
+				indice = len(puestos)
+				indices[cambio.JugadorID] = indice
+				puestos = append(puestos, models.PuestoClasificacion{
+					JugadorID:  cambio.JugadorID,
+					Puntuacion: s.estadisticas[claveEstadistica{cambio.JugadorID, tipo}].Puntuacion,
+				})
+			}
+			puestos[indice].Jugadas++
+			if cambio.Resultado == models.ResultadoVictoria {
+				puestos[indice].Victorias++
+			}
+		}
+	}
+
+	sort.Slice(puestos, func(i, j int) bool {
+		a, b := valorClasificacion(puestos[i], orden), valorClasificacion(puestos[j], orden)
+		if a != b {
+			return a > b
+		}
+		return puestos[i].JugadorID < puestos[j].JugadorID
+	})
+	for i := range puestos {
+		puestos[i].Nombre = s.jugadores[puestos[i].JugadorID].Name
+		puestos[i].Posicion = i + 1
+		if i > 0 && valorClasificacion(puestos[i], orden) == valorClasificacion(puestos[i-1], orden) {
+			puestos[i].Posicion = puestos[i-1].Posicion
+		}
+	}
+	return puestos
+}
+
+func (s *Memoria) ListarHistorialPuntuacion(jugadorID uint, tipo string, limite int) ([]models.HistorialPuntuacion, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var historial []models.HistorialPuntuacion
+	for i := len(s.historial) - 1; i >= 0 && len(historial) < limite; i-- {
+		if s.historial[i].JugadorID == jugadorID && s.historial[i].TipoJuego == tipo {
+			historial = append(historial, s.historial[i])
+		}
+	}
+	return historial, nil
+}
+
+func (s *Memoria) ListarEstadisticas(jugadorID uint) ([]models.Estadistica, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var estadisticas []models.Estadistica
+	for clave, estadistica := range s.estadisticas {
+		if clave.jugadorID == jugadorID {
+			estadisticas = append(estadisticas, estadistica)
+		}
+	}
+	sort.Slice(estadisticas, func(i, j int) bool { return estadisticas[i].TipoJuego < estadisticas[j].TipoJuego })
+	return estadisticas, nil
+}
+
+// guardarPartida actualiza una partida guardada; requiere el mutex
+func (s *Memoria) guardarPartida(partida *models.Partida, movimientos []models.Movimiento) error {
+	guardada, existe := s.partidas[partida.ID]
+	if !existe {
+		return ErrNoEncontrado
+	}
+
+	guardada.Estado = partida.Estado
+	guardada.GanadorID = partida.GanadorID
+	guardada.Actualizado = partida.Actualizado
+	guardada.TerminadoEn = partida.TerminadoEn
+	guardada.Movimientos = partida.Movimientos
+	guardada.Tablero.Datos = partida.Tablero.Datos
+	guardada = copiarPartida(guardada)
+	for _, participante := range partida.Participantes {
+		for i := range guardada.Participantes {
+			if guardada.Participantes[i].Asiento == participante.Asiento {
+				guardada.Participantes[i].JugadorID = participante.JugadorID
+				guardada.Participantes[i].Nombre = participante.Nombre
+				guardada.Participantes[i].Resultado = participante.Resultado
+			}
+		}
+	}
+	s.partidas[partida.ID] = guardada
+	s.movimientos[partida.ID] = append(s.movimientos[partida.ID], movimientos...)
+	return nil
+}
+
+func (s *Memoria) ListarMovimientos(partidaID string) ([]models.Movimiento, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return append([]models.Movimiento(nil), s.movimientos[partidaID]...), nil
+}
+
+func (s *Memoria) CrearMensaje(mensaje *models.MensajeChat) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	mensaje.ID = s.nuevoID("partida_mensajes")
+	s.mensajes[mensaje.PartidaID] = append(s.mensajes[mensaje.PartidaID], *mensaje)
+	return nil
+}
+
+func (s *Memoria) ListarMensajes(partidaID, canal string, antesDe uint, limite int) ([]models.MensajeChat, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var mensajes []models.MensajeChat
+	todos := s.mensajes[partidaID]
+	for i := len(todos) - 1; i >= 0 && len(mensajes) < limite; i-- {
+		if todos[i].Canal == canal && (antesDe == 0 || todos[i].ID < antesDe) {
+			mensajes = append([]models.MensajeChat{todos[i]}, mensajes...)
+		}
+	}
+	return mensajes, nil
+}
+
+func (s *Memoria) Silenciar(silencio models.Silencio) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	silencio.ID = 0
+	s.silencios[silencio] = true
+	return nil
+}
+
+func (s *Memoria) QuitarSilencio(silencio models.Silencio) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	silencio.ID = 0
+	delete(s.silencios, silencio)
+	return nil
+}
+
+func (s *Memoria) ListarSilencios(partidaID string) ([]models.Silencio, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	var silencios []models.Silencio
+	for silencio := range s.silencios {
+		if silencio.PartidaID == partidaID {
+			silencios = append(silencios, silencio)
+		}
+	}
+	return silencios, nil
+}
+
+func (s *Memoria) CrearJugador(jugador *models.Jugador) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	jugador.ID = s.nuevoID("jugadores")
+	s.jugadores[jugador.ID] = *jugador
+	return nil
+}
+
+func (s *Memoria) ObtenerJugador(id uint) (*models.Jugador, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	jugador, existe := s.jugadores[id]
+	if !existe {
+		return nil, ErrNoEncontrado
+	}
+	return &jugador, nil
+}
+
+func (s *Memoria) BuscarJugadorPorEmail(email string) (*models.Jugador, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for _, jugador := range s.jugadores {
+		if jugador.Email == email {
+			return &jugador, nil
+		}
+	}
+	return nil, ErrNoEncontrado
+}
+
+func (s *Memoria) CrearSesion(sesion *models.Sesion) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sesion.ID = s.nuevoID("sesiones")
+	s.sesiones[sesion.ID] = *sesion
+	return nil
+}
+
+func (s *Memoria) BuscarSesionPorAcceso(hash string) (*models.Sesion, error) {
+	return s.buscarSesion(func(sesion models.Sesion) bool { return sesion.AccesoHash == hash })
+}
+
+func (s *Memoria) BuscarSesionPorRefresco(hash string) (*models.Sesion, error) {
+	return s.buscarSesion(func(sesion models.Sesion) bool { return sesion.RefrescoHash == hash })
+}
+
+func (s *Memoria) BorrarSesion(id uint) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.sesiones, id)
+	return nil
+}
+
+func (s *Memoria) buscarSesion(coincide func(models.Sesion) bool) (*models.Sesion, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for _, sesion := range s.sesiones {
+		if coincide(sesion) {
+			return &sesion, nil
+		}
+	}
+	return nil, ErrNoEncontrado
+}
+
+func (s *Memoria) CrearCodigoQR(codigo *models.CodigoQR) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	codigo.ID = s.nuevoID("codigos_qr")
+	s.codigosQR[codigo.Codigo] = *codigo
+	return nil
+}
+
+func (s *Memoria) ObtenerCodigoQR(codigo string) (*models.CodigoQR, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	codigoQR, existe := s.codigosQR[codigo]
+	if !existe {
+		return nil, ErrNoEncontrado
+	}
+	return &codigoQR, nil
+}
+
+func (s *Memoria) GuardarCodigoQR(codigo *models.CodigoQR) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, existe := s.codigosQR[codigo.Codigo]; !existe {
+		return ErrNoEncontrado
+	}
+	s.codigosQR[codigo.Codigo] = *codigo
+	return nil
+}
+
+func (s *Memoria) BorrarCodigosQRCaducados(antes time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for clave, codigo := range s.codigosQR {
+		if codigo.ExpiraEn.Before(antes) {
+			delete(s.codigosQR, clave)
+		}
+	}
+	return nil
+}
+
+func (s *Memoria) Close() error {
+	return nil
+}
+
+// nuevoID asigna el siguiente ID autoincremental de una tabla; requiere el mutex
+func (s *Memoria) nuevoID(tabla string) uint {
+	s.ultimoID[tabla]++
+	return s.ultimoID[tabla]
+}
+
+// copiarPartida evita que quien llama comparta el slice de participantes con el mapa
+func copiarPartida(partida models.Partida) models.Partida {
+	partida.Participantes = append([]models.Participante(nil), partida.Participantes...)
+	return partida
+}
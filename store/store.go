@@ -0,0 +1,156 @@
+// Package store abstrae dónde se guardan las partidas y los jugadores. Hay una
+// implementación en memoria (pruebas) y otra con GORM para SQLite y MySQL.
+package store
+
+import (
+	"errors"
+	"fmt"
+	"juego/db"
+	"juego/models"
+	"sort"
+	"time"
+)
+
+// Drivers de almacenamiento que se pueden elegir al arrancar
+const (
+	DriverMemoria = "memoria"
+	DriverSQLite  = "sqlite"
+	DriverMySQL   = "mysql"
+)
+
+var ErrNoEncontrado = errors.New("registro no encontrado")
+
+// FiltroPartidas selecciona las partidas terminadas de un jugador
+type FiltroPartidas struct {
+	TipoJuego      string     // Vacío para todos los juegos
+	Desde          *time.Time // Terminadas a partir de esta fecha
+	Hasta          *time.Time // Terminadas antes de esta fecha
+	Limite         int
+	Desplazamiento int
+}
+
+// GameStore es el almacenamiento que usan los servicios de juegos y jugadores
+type GameStore interface {
+	// CrearPartida guarda una partida nueva junto con sus participantes y su tablero
+	CrearPartida(partida *models.Partida) error
+	// ObtenerPartida devuelve una partida con sus participantes (por asiento) y su tablero
+	ObtenerPartida(id string) (*models.Partida, error)
+	// ListarSalas devuelve las partidas públicas que esperan jugadores, las más
+	// recientes primero; con tipo vacío se listan todos los juegos
+	ListarSalas(tipo string, limite, desplazamiento int) ([]models.Partida, error)
+	// ListarPartidasJugador devuelve las partidas terminadas (también las abandonadas)
+	// en las que se sentó el jugador, con sus participantes y las más recientes
+	// primero, y cuántas cumplen el filtro en total
+	ListarPartidasJugador(jugadorID uint, filtro FiltroPartidas) ([]models.Partida, int, error)
+	// BuscarPartidaPorCodigo busca una partida por su código de invitación
+	BuscarPartidaPorCodigo(codigo string) (*models.Partida, error)
+	// GuardarPartida actualiza el estado, el tablero y los participantes de una partida y
+	// añade al historial los movimientos nuevos, todo a la vez
+	GuardarPartida(partida *models.Partida, movimientos ...models.Movimiento) error
+	// TerminarPartida guarda como GuardarPartida una partida que acaba de terminar y,
+	// en la misma transacción, pasa a actualizar las estadísticas del tipo de juego
+	// de sus jugadores (indexadas por ID de jugador; las que no existían llegan a cero
+	// y con la puntuación inicial). Apunta en el historial la puntuación de cada jugador
+	// antes y después de la partida
+	TerminarPartida(partida *models.Partida, actualizar func(map[uint]*models.Estadistica) error, movimientos ...models.Movimiento) error
+	// ListarEstadisticas devuelve las estadísticas de un jugador en cada tipo de juego que ha jugado
+	ListarEstadisticas(jugadorID uint) ([]models.Estadistica, error)
+	// ListarClasificacion devuelve una página de la clasificación de un tipo de juego
+	// y cuántos jugadores hay en ella. Con desde solo entran las partidas terminadas
+	// a partir de esa fecha
+	ListarClasificacion(tipo, orden string, desde *time.Time, limite, desplazamiento int) ([]models.PuestoClasificacion, int, error)
+	// PosicionClasificacion devuelve el puesto de un jugador en la clasificación, o
+	// ErrNoEncontrado si no ha terminado ninguna partida en ella
+	PosicionClasificacion(tipo, orden string, desde *time.Time, jugadorID uint) (*models.PuestoClasificacion, error)
+	// ListarHistorialPuntuacion devuelve los últimos cambios de puntuación de un
+	// jugador en un tipo de juego, los más recientes primero
+	ListarHistorialPuntuacion(jugadorID uint, tipo string, limite int) ([]models.HistorialPuntuacion, error)
+	// ListarMovimientos devuelve el historial de una partida ordenado por número
+	ListarMovimientos(partidaID string) ([]models.Movimiento, error)
+
+	// CrearMensaje guarda un mensaje del chat de una partida y le asigna su ID
+	CrearMensaje(mensaje *models.MensajeChat) error
+	// ListarMensajes devuelve los últimos mensajes de un canal anteriores al ID
+	// indicado (0 para los más recientes), ordenados del más antiguo al más nuevo
+	ListarMensajes(partidaID, canal string, antesDe uint, limite int) ([]models.MensajeChat, error)
+	// Silenciar guarda que un jugador silencia a otro en una partida; repetirlo no hace nada
+	Silenciar(silencio models.Silencio) error
+	// QuitarSilencio vuelve a mostrar los mensajes del jugador silenciado
+	QuitarSilencio(silencio models.Silencio) error
+	// ListarSilencios devuelve todos los silencios de una partida
+	ListarSilencios(partidaID string) ([]models.Silencio, error)
+
+	// CrearJugador da de alta un jugador y le asigna su ID
+	CrearJugador(jugador *models.Jugador) error
+	// ObtenerJugador busca un jugador por su ID
+	ObtenerJugador(id uint) (*models.Jugador, error)
+	// BuscarJugadorPorEmail busca un jugador por su correo
+	BuscarJugadorPorEmail(email string) (*models.Jugador, error)
+
+	// CrearSesion guarda una sesión nueva y le asigna su ID
+	CrearSesion(sesion *models.Sesion) error
+	// BuscarSesionPorAcceso busca la sesión a la que pertenece el hash de un token de acceso
+	BuscarSesionPorAcceso(hash string) (*models.Sesion, error)
+	// BuscarSesionPorRefresco busca la sesión a la que pertenece el hash de un token de refresco
+	BuscarSesionPorRefresco(hash string) (*models.Sesion, error)
+	// BorrarSesion elimina una sesión; sus tokens dejan de valer
+	BorrarSesion(id uint) error
+
+	// CrearCodigoQR guarda un código QR nuevo y le asigna su ID
+	CrearCodigoQR(codigo *models.CodigoQR) error
+	// ObtenerCodigoQR busca un código QR por su valor
+	ObtenerCodigoQR(codigo string) (*models.CodigoQR, error)
+	// GuardarCodigoQR actualiza el estado de un código QR
+	GuardarCodigoQR(codigo *models.CodigoQR) error
+	// BorrarCodigosQRCaducados elimina los códigos que caducaron antes de la fecha indicada
+	BorrarCodigosQRCaducados(antes time.Time) error
+
+	Close() error
+}
+
+// Open crea el almacenamiento del driver indicado
+func Open(driver, dsn string) (GameStore, error) {
+	switch driver {
+	case DriverMemoria:
+		return NewMemoria(), nil
+	case DriverSQLite, DriverMySQL:
+		if err := db.Connect(driver, dsn); err != nil {
+			return nil, err
+		}
+		return NewGorm(db.DB), nil
+	default:
+		return nil, fmt.Errorf("driver de almacenamiento desconocido: %q", driver)
+	}
+}
+
+// valorClasificacion es el valor por el que se ordena un puesto de la clasificación
+func valorClasificacion(puesto models.PuestoClasificacion, orden string) int {
+	if orden == models.OrdenVictorias {
+		return puesto.Victorias
+	}
+	return puesto.Puntuacion
+}
+
+// historialPuntuacion prepara las entradas del historial de puntuación de los
+// jugadores de una partida terminada, ordenadas por jugador
+func historialPuntuacion(partida *models.Partida, estadisticas map[uint]*models.Estadistica, anteriores map[uint]int) []models.HistorialPuntuacion {
+	resultados := make(map[uint]string, len(partida.Participantes))
+	for _, participante := range partida.Participantes {
+		resultados[participante.JugadorID] = participante.Resultado
+	}
+
+	historial := make([]models.HistorialPuntuacion, 0, len(estadisticas))
+	for jugadorID, estadistica := range estadisticas {
+		historial = append(historial, models.HistorialPuntuacion{
+			JugadorID:  jugadorID,
+			TipoJuego:  partida.TipoJuego,
+			PartidaID:  partida.ID,
+			Resultado:  resultados[jugadorID],
+			Anterior:   anteriores[jugadorID],
+			Puntuacion: estadistica.Puntuacion,
+			CreadoEn:   partida.Actualizado,
+		})
+	}
+	sort.Slice(historial, func(i, j int) bool { return historial[i].JugadorID < historial[j].JugadorID })
+	return historial
+}
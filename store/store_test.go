@@ -0,0 +1,340 @@
+package store
+
+import (
+	"errors"
+	"juego/models"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// enCadaAlmacen repite la prueba sobre la memoria y sobre SQLite, que deben
+// comportarse igual a ojos de los servicios
+func enCadaAlmacen(t *testing.T, prueba func(t *testing.T, s GameStore)) {
+	t.Run(DriverMemoria, func(t *testing.T) {
+		prueba(t, NewMemoria())
+	})
+	t.Run(DriverSQLite, func(t *testing.T) {
+		s, err := Open(DriverSQLite, filepath.Join(t.TempDir(), "juego.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { s.Close() })
+		prueba(t, s)
+	})
+}
+
+// crearJugadores da de alta jugadores con los nombres indicados y devuelve sus IDs
+func crearJugadores(t *testing.T, s GameStore, nombres ...string) []uint {
+	t.Helper()
+	ids := make([]uint, len(nombres))
+	for i, nombre := range nombres {
+		jugador := &models.Jugador{Name: nombre, Email: nombre + "@ejemplo.com"}
+		if err := s.CrearJugador(jugador); err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = jugador.ID
+	}
+	return ids
+}
+
+// crearPartida guarda una partida en curso de Conecta Cuatro con los jugadores
+// sentados en orden; un ID 0 deja el asiento libre
+func crearPartida(t *testing.T, s GameStore, id string, jugadores ...uint) *models.Partida {
+	t.Helper()
+	ahora := time.Now().UTC().Truncate(time.Second)
+	partida := &models.Partida{
+		ID:          id,
+		TipoJuego:   "conecta_cuatro",
+		Estado:      models.EstadoEnProgreso,
+		CreadoEn:    ahora,
+		Actualizado: ahora,
+		Tablero:     models.TableroPartida{PartidaID: id, Datos: `{"turno":0}`, Inicial: `{"turno":0}`},
+	}
+	for asiento, jugadorID := range jugadores {
+		partida.Participantes = append(partida.Participantes, models.Participante{PartidaID: id, Asiento: asiento, JugadorID: jugadorID})
+	}
+	if err := s.CrearPartida(partida); err != nil {
+		t.Fatal(err)
+	}
+	return partida
+}
+
+// terminar cierra una partida de dos jugadores con el ganador indicado (-1 para
+// empate) y suma en las estadísticas una partida y los puntos de cada asiento
+func terminar(t *testing.T, s GameStore, partida *models.Partida, ganador int, puntos [2]int, fecha time.Time) {
+	t.Helper()
+	partida.Estado = models.EstadoEmpate
+	partida.Actualizado = fecha
+	partida.TerminadoEn = &fecha
+	for i := range partida.Participantes {
+		switch {
+		case ganador < 0:
+			partida.Participantes[i].Resultado = models.ResultadoEmpate
+		case i == ganador:
+			partida.Estado = models.EstadoTerminado
+			partida.GanadorID = &partida.Participantes[i].JugadorID
+			partida.Participantes[i].Resultado = models.ResultadoVictoria
+		default:
+			partida.Participantes[i].Resultado = models.ResultadoDerrota
+		}
+	}
+	err := s.TerminarPartida(partida, func(estadisticas map[uint]*models.Estadistica) error {
+		for asiento, participante := range partida.Participantes {
+			estadistica := estadisticas[participante.JugadorID]
+			estadistica.Jugadas++
+			if asiento == ganador {
+				estadistica.Victorias++
+			}
+			estadistica.Puntuacion += puntos[asiento]
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCrearYObtenerPartida(t *testing.T) {
+	enCadaAlmacen(t, func(t *testing.T, s GameStore) {
+		ids := crearJugadores(t, s, "ana", "bea")
+		crearPartida(t, s, "p1", ids[0], 0)
+		codigo := "ABC123"
+		invitacion := &models.Partida{ID: "p2", TipoJuego: "conecta_cuatro", Codigo: &codigo, Estado: models.EstadoEsperando,
+			Tablero: models.TableroPartida{PartidaID: "p2", Datos: "{}", Inicial: "{}"}}
+		if err := s.CrearPartida(invitacion); err != nil {
+			t.Fatal(err)
+		}
+
+		guardada, err := s.ObtenerPartida("p1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if guardada.TipoJuego != "conecta_cuatro" || guardada.Estado != models.EstadoEnProgreso || guardada.Tablero.Datos != `{"turno":0}` {
+			t.Fatalf("partida guardada distinta: %+v", guardada)
+		}
+		if len(guardada.Participantes) != 2 || guardada.Participantes[0].JugadorID != ids[0] || guardada.Participantes[1].JugadorID != 0 {
+			t.Fatalf("participantes %+v", guardada.Participantes)
+		}
+
+		if _, err := s.ObtenerPartida("no-existe"); !errors.Is(err, ErrNoEncontrado) {
+			t.Fatalf("error %v, se esperaba %v", err, ErrNoEncontrado)
+		}
+		if encontrada, err := s.BuscarPartidaPorCodigo(codigo); err != nil || encontrada.ID != "p2" {
+			t.Fatalf("partida %+v por código (%v)", encontrada, err)
+		}
+		if _, err := s.BuscarPartidaPorCodigo("ZZZ999"); !errors.Is(err, ErrNoEncontrado) {
+			t.Fatalf("error %v, se esperaba %v", err, ErrNoEncontrado)
+		}
+	})
+}
+
+func TestGuardarPartida(t *testing.T) {
+	enCadaAlmacen(t, func(t *testing.T, s GameStore) {
+		ids := crearJugadores(t, s, "ana", "bea")
+		partida := crearPartida(t, s, "p1", ids[0], 0)
+
+		partida.Participantes[1].JugadorID = ids[1]
+		partida.Participantes[1].Nombre = "bea"
+		partida.Tablero.Datos = `{"turno":1}`
+		partida.Movimientos = 2
+		movimientos := []models.Movimiento{
+			{PartidaID: "p1", Numero: 1, Tipo: models.MovimientoUnion, Asiento: 1, JugadorID: ids[1], Datos: "{}"},
+			{PartidaID: "p1", Numero: 2, Tipo: models.MovimientoJugada, Asiento: 0, JugadorID: ids[0], Datos: `{"columna":3}`},
+		}
+		if err := s.GuardarPartida(partida, movimientos...); err != nil {
+			t.Fatal(err)
+		}
+
+		guardada, err := s.ObtenerPartida("p1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if guardada.Participantes[1].JugadorID != ids[1] || guardada.Participantes[1].Nombre != "bea" {
+			t.Fatalf("el asiento libre no se ha ocupado: %+v", guardada.Participantes)
+		}
+		if guardada.Tablero.Datos != `{"turno":1}` || guardada.Tablero.Inicial != `{"turno":0}` || guardada.Movimientos != 2 {
+			t.Fatalf("tablero %+v con %d movimientos", guardada.Tablero, guardada.Movimientos)
+		}
+
+		historial, err := s.ListarMovimientos("p1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(historial) != 2 || historial[0].Numero != 1 || historial[1].Datos != `{"columna":3}` {
+			t.Fatalf("historial %+v", historial)
+		}
+	})
+}
+
+func TestTerminarPartida(t *testing.T) {
+	enCadaAlmacen(t, func(t *testing.T, s GameStore) {
+		ids := crearJugadores(t, s, "ana", "bea")
+		partida := crearPartida(t, s, "p1", ids...)
+
+		// Las estadísticas que no existían llegan a cero y con la puntuación inicial
+		fecha := time.Now().UTC().Truncate(time.Second)
+		partida.Estado = models.EstadoTerminado
+		partida.TerminadoEn = &fecha
+		partida.Participantes[0].Resultado = models.ResultadoVictoria
+		partida.Participantes[1].Resultado = models.ResultadoDerrota
+		err := s.TerminarPartida(partida, func(estadisticas map[uint]*models.Estadistica) error {
+			if len(estadisticas) != 2 {
+				t.Fatalf("%d estadísticas, se esperaban 2", len(estadisticas))
+			}
+			for _, estadistica := range estadisticas {
+				if estadistica.Jugadas != 0 || estadistica.Puntuacion != models.PuntuacionInicial {
+					t.Fatalf("estadística nueva %+v", estadistica)
+				}
+			}
+			estadisticas[ids[0]].Jugadas, estadisticas[ids[0]].Victorias, estadisticas[ids[0]].Puntuacion = 1, 1, 1516
+			estadisticas[ids[1]].Jugadas, estadisticas[ids[1]].Derrotas, estadisticas[ids[1]].Puntuacion = 1, 1, 1484
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		guardada, err := s.ObtenerPartida("p1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if guardada.Estado != models.EstadoTerminado || guardada.TerminadoEn == nil || guardada.Participantes[0].Resultado != models.ResultadoVictoria {
+			t.Fatalf("partida terminada guardada como %+v", guardada)
+		}
+
+		estadisticas, err := s.ListarEstadisticas(ids[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(estadisticas) != 1 || estadisticas[0].Derrotas != 1 || estadisticas[0].Puntuacion != 1484 {
+			t.Fatalf("estadísticas %+v", estadisticas)
+		}
+
+		historial, err := s.ListarHistorialPuntuacion(ids[0], "conecta_cuatro", 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(historial) != 1 || historial[0].Anterior != models.PuntuacionInicial || historial[0].Puntuacion != 1516 ||
+			historial[0].Resultado != models.ResultadoVictoria || historial[0].PartidaID != "p1" {
+			t.Fatalf("historial %+v", historial)
+		}
+	})
+}
+
+func TestTerminarPartidaFallidaNoGuardaNada(t *testing.T) {
+	enCadaAlmacen(t, func(t *testing.T, s GameStore) {
+		ids := crearJugadores(t, s, "ana", "bea")
+		partida := crearPartida(t, s, "p1", ids...)
+
+		fallo := errors.New("fallo al puntuar")
+		partida.Estado = models.EstadoTerminado
+		partida.Movimientos = 1
+		err := s.TerminarPartida(partida, func(estadisticas map[uint]*models.Estadistica) error {
+			estadisticas[ids[0]].Jugadas = 1
+			return fallo
+		}, models.Movimiento{PartidaID: "p1", Numero: 1, Tipo: models.MovimientoJugada})
+		if !errors.Is(err, fallo) {
+			t.Fatalf("error %v, se esperaba %v", err, fallo)
+		}
+
+		guardada, err := s.ObtenerPartida("p1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if guardada.Estado != models.EstadoEnProgreso || guardada.Movimientos != 0 {
+			t.Fatalf("la partida se ha guardado a medias: %+v", guardada)
+		}
+		if movimientos, _ := s.ListarMovimientos("p1"); len(movimientos) != 0 {
+			t.Fatalf("movimientos guardados: %+v", movimientos)
+		}
+		if estadisticas, _ := s.ListarEstadisticas(ids[0]); len(estadisticas) != 0 {
+			t.Fatalf("estadísticas guardadas: %+v", estadisticas)
+		}
+		if historial, _ := s.ListarHistorialPuntuacion(ids[0], "conecta_cuatro", 10); len(historial) != 0 {
+			t.Fatalf("historial guardado: %+v", historial)
+		}
+	})
+}
+
+func TestClasificacion(t *testing.T) {
+	enCadaAlmacen(t, func(t *testing.T, s GameStore) {
+		ids := crearJugadores(t, s, "ana", "bea", "carla", "dani", "eva")
+		hace := time.Now().UTC().Truncate(time.Second).Add(-30 * 24 * time.Hour)
+		ahora := time.Now().UTC().Truncate(time.Second)
+
+		// Hace un mes: ana gana a bea. Ahora: carla gana a dani y ana empata con carla
+		terminar(t, s, crearPartida(t, s, "p1", ids[0], ids[1]), 0, [2]int{20, -20}, hace)
+		terminar(t, s, crearPartida(t, s, "p2", ids[2], ids[3]), 0, [2]int{20, -20}, ahora)
+		terminar(t, s, crearPartida(t, s, "p3", ids[0], ids[2]), -1, [2]int{0, 0}, ahora)
+
+		// Puntuaciones: ana 1520, carla 1520, bea 1480, dani 1480; eva no ha jugado
+		puestos, total, err := s.ListarClasificacion("conecta_cuatro", models.OrdenPuntuacion, nil, 10, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != 4 || len(puestos) != 4 {
+			t.Fatalf("%d puestos de %d, se esperaban 4", len(puestos), total)
+		}
+		esperados := []struct {
+			posicion  int
+			jugadorID uint
+			nombre    string
+		}{{1, ids[0], "ana"}, {1, ids[2], "carla"}, {3, ids[1], "bea"}, {3, ids[3], "dani"}}
+		for i, esperado := range esperados {
+			if puestos[i].Posicion != esperado.posicion || puestos[i].JugadorID != esperado.jugadorID || puestos[i].Nombre != esperado.nombre {
+				t.Fatalf("puesto %d: %+v, se esperaba %+v", i, puestos[i], esperado)
+			}
+		}
+
+		// La segunda página empieza empatada con la primera y conserva la posición compartida
+		pagina, total, err := s.ListarClasificacion("conecta_cuatro", models.OrdenPuntuacion, nil, 2, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != 4 || len(pagina) != 2 || pagina[0].JugadorID != ids[2] || pagina[0].Posicion != 1 || pagina[1].Posicion != 3 {
+			t.Fatalf("página %+v", pagina)
+		}
+
+		// Por victorias: ana y carla llevan una cada una
+		porVictorias, _, err := s.ListarClasificacion("conecta_cuatro", models.OrdenVictorias, nil, 10, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if porVictorias[0].Victorias != 1 || porVictorias[1].Victorias != 1 || porVictorias[1].Posicion != 1 || porVictorias[2].Posicion != 3 {
+			t.Fatalf("clasificación por victorias %+v", porVictorias)
+		}
+
+		// En la última semana solo cuentan p2 y p3, pero la puntuación es la actual
+		semana := ahora.Add(-7 * 24 * time.Hour)
+		recientes, total, err := s.ListarClasificacion("conecta_cuatro", models.OrdenVictorias, &semana, 10, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != 3 || recientes[0].JugadorID != ids[2] || recientes[0].Victorias != 1 || recientes[0].Jugadas != 2 {
+			t.Fatalf("clasificación de la semana %+v (%d)", recientes, total)
+		}
+		for _, puesto := range recientes {
+			if puesto.JugadorID == ids[1] {
+				t.Fatalf("bea no ha jugado esta semana: %+v", recientes)
+			}
+			if puesto.JugadorID == ids[0] && (puesto.Victorias != 0 || puesto.Puntuacion != 1520) {
+				t.Fatalf("ana en la semana: %+v", puesto)
+			}
+		}
+
+		puesto, err := s.PosicionClasificacion("conecta_cuatro", models.OrdenPuntuacion, nil, ids[3])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if puesto.Posicion != 3 || puesto.Puntuacion != 1480 {
+			t.Fatalf("puesto de dani %+v", puesto)
+		}
+		if _, err := s.PosicionClasificacion("conecta_cuatro", models.OrdenPuntuacion, nil, ids[4]); !errors.Is(err, ErrNoEncontrado) {
+			t.Fatalf("error %v, se esperaba %v", err, ErrNoEncontrado)
+		}
+		if _, err := s.PosicionClasificacion("conecta_cuatro", models.OrdenPuntuacion, &semana, ids[1]); !errors.Is(err, ErrNoEncontrado) {
+			t.Fatalf("error %v, se esperaba %v", err, ErrNoEncontrado)
+		}
+	})
+}